@@ -0,0 +1,165 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// accessScopeType is the type of the set of library repositories an access control entry applies to.
+type accessScopeType int
+
+// The access control entry scopes, in order of increasing specificity.
+const (
+	ownerAccessScope      accessScopeType = iota // All repositories of the account.
+	patternAccessScope                           // Repositories with a path matching the entry's pattern or regular expression.
+	repositoryAccessScope                        // A single repository.
+)
+
+// scope returns the scope of the access control entry.
+func (accessData accessDataType) scope() accessScopeType {
+	if accessData.Repository != "" {
+		return repositoryAccessScope
+	}
+	if accessData.Pattern != "" || accessData.Regex != "" {
+		return patternAccessScope
+	}
+
+	return ownerAccessScope
+}
+
+// subject returns a description of the library repositories the access control entry applies to, for use in messages.
+func (accessData accessDataType) subject() string {
+	switch accessData.scope() {
+	case repositoryAccessScope:
+		return fmt.Sprintf("library repository `%s/%s/%s`", accessData.Host, accessData.Name, accessData.Repository)
+	case patternAccessScope:
+		if accessData.Pattern != "" {
+			return fmt.Sprintf("library repositories matching `%s`", accessData.Pattern)
+		}
+		return fmt.Sprintf("library repositories matching `%s`", accessData.Regex)
+	default:
+		return fmt.Sprintf("library repository owner `%s/%s`", accessData.Host, accessData.Name)
+	}
+}
+
+// appliesTo returns whether the access control entry applies to the library repository at the normalized URL.
+func (accessData accessDataType) appliesTo(normalizedURL url.URL) bool {
+	repositorySlug := normalizedURL.Host + strings.TrimSuffix(normalizedURL.Path, ".git")
+	switch accessData.scope() {
+	case repositoryAccessScope:
+		return repositorySlug == fmt.Sprintf("%s/%s/%s", accessData.Host, accessData.Name, accessData.Repository)
+	case patternAccessScope:
+		if accessData.Pattern != "" {
+			match, err := path.Match(accessData.Pattern, repositorySlug)
+			if err != nil || !match {
+				return false
+			}
+		}
+		if accessData.Regex != "" {
+			return accessData.compiledRegex.MatchString(repositorySlug)
+		}
+		return true
+	default:
+		return uRLIsUnder(normalizedURL, []string{fmt.Sprintf("%s/%s", accessData.Host, accessData.Name)})
+	}
+}
+
+// compileAccessPatterns checks the syntax of the patterns and regular expressions of the access control entries and
+// prepares the regular expressions for matching.
+func compileAccessPatterns(accessList []accessDataType) error {
+	for accessDataIndex := range accessList {
+		accessData := &accessList[accessDataIndex]
+		if accessData.Pattern != "" {
+			if _, err := path.Match(accessData.Pattern, ""); err != nil {
+				return fmt.Errorf("Invalid pattern %s: %s", accessData.Pattern, err)
+			}
+		}
+		if accessData.Regex != "" {
+			// The regular expression must match the complete path.
+			compiledRegex, err := regexp.Compile("^(?:" + accessData.Regex + ")$")
+			if err != nil {
+				return fmt.Errorf("Invalid regex %s: %s", accessData.Regex, err)
+			}
+			accessData.compiledRegex = compiledRegex
+		}
+	}
+
+	return nil
+}
+
+// repositoryAccessData returns the access control entry that determines the access level for the library repository at
+// the normalized URL, and whether any entry applies. The most specific applicable entry takes precedence and a deny
+// entry takes precedence over other entries of the same specificity.
+func repositoryAccessData(normalizedURL url.URL, accessList []accessDataType) (accessDataType, bool) {
+	var selectedAccessData accessDataType
+	found := false
+	for _, accessData := range accessList {
+		if !accessData.appliesTo(normalizedURL) {
+			continue
+		}
+
+		if !found ||
+			accessData.scope() > selectedAccessData.scope() ||
+			(accessData.scope() == selectedAccessData.scope() && accessData.Access == Deny && selectedAccessData.Access != Deny) {
+			selectedAccessData = accessData
+			found = true
+		}
+	}
+
+	return selectedAccessData, found
+}
+
+// inEffectAt returns whether the access control entry is in effect at the given time.
+func (accessData accessDataType) inEffectAt(now time.Time) bool {
+	if accessData.Since != nil && now.Before(*accessData.Since) {
+		return false
+	}
+
+	return !accessData.expiredAt(now)
+}
+
+// expiredAt returns whether the access control entry has expired at the given time.
+func (accessData accessDataType) expiredAt(now time.Time) bool {
+	return accessData.Until != nil && !now.Before(*accessData.Until)
+}
+
+// partitionAccessList returns the access control entries that are in effect at the given time and those that have
+// expired. Entries that have not yet taken effect are in neither list.
+func partitionAccessList(accessList []accessDataType, now time.Time) ([]accessDataType, []accessDataType) {
+	var inEffectAccessList []accessDataType
+	var expiredAccessList []accessDataType
+	for _, accessData := range accessList {
+		if accessData.inEffectAt(now) {
+			inEffectAccessList = append(inEffectAccessList, accessData)
+		} else if accessData.expiredAt(now) {
+			expiredAccessList = append(expiredAccessList, accessData)
+		}
+	}
+
+	return inEffectAccessList, expiredAccessList
+}
@@ -0,0 +1,163 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_partitionAccessList(t *testing.T) {
+	rawAccessList := []byte(`
+- access: deny
+  host: github.com
+  name: Permanent
+  reference: https://example.com
+- access: deny
+  host: github.com
+  name: Suspended
+  reference: https://example.com
+  until: 2021-06-01
+- access: allow
+  host: github.com
+  name: Granted
+  reference: https://example.com
+  since: 2021-03-01T12:00:00Z
+  until: 2021-09-01T12:00:00Z
+- access: deny
+  host: github.com
+  name: Future
+  reference: https://example.com
+  since: 2021-12-01T00:00:00Z
+`)
+	var accessList []accessDataType
+	require.Nil(t, yaml.Unmarshal(rawAccessList, &accessList))
+	require.NotNil(t, accessList[1].Until)
+	assert.Equal(t, time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), accessList[1].Until.UTC())
+
+	accessListNames := func(accessList []accessDataType) []string {
+		var names []string
+		for _, accessData := range accessList {
+			names = append(names, accessData.Name)
+		}
+		return names
+	}
+
+	testTables := []struct {
+		testName                   string
+		now                        time.Time
+		expectedInEffectAccessList []string
+		expectedExpiredAccessList  []string
+	}{
+		{"Before all", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), []string{"Permanent", "Suspended"}, nil},
+		{"Start of grant", time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC), []string{"Permanent", "Suspended", "Granted"}, nil},
+		{"End of suspension", time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), []string{"Permanent", "Granted"}, []string{"Suspended"}},
+		{"After all", time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), []string{"Permanent", "Future"}, []string{"Suspended", "Granted"}},
+	}
+
+	for _, testTable := range testTables {
+		inEffectAccessList, expiredAccessList := partitionAccessList(accessList, testTable.now)
+		assert.Equal(t, testTable.expectedInEffectAccessList, accessListNames(inEffectAccessList), testTable.testName)
+		assert.Equal(t, testTable.expectedExpiredAccessList, accessListNames(expiredAccessList), testTable.testName)
+	}
+}
+
+func Test_repositoryAccessData(t *testing.T) {
+	rawAccessList := []byte(`
+- access: deny
+  host: github.com
+  name: DeniedOwner
+  reference: https://example.com/denied-owner
+- access: allow
+  host: github.com
+  name: DeniedOwner
+  repository: AllowedRepository
+  reference: https://example.com/allowed-repository
+- access: deny
+  host: github.com
+  name: SomeOwner
+  repository: DeniedRepository
+  reference: https://example.com/denied-repository
+- access: deny
+  pattern: github.com/*/*-fork
+  reference: https://example.com/denied-pattern
+- access: allow
+  pattern: github.com/SomeOwner/*
+  reference: https://example.com/allowed-pattern
+- access: deny
+  regex: gitlab\.com/spam-[0-9]+/.*
+  reference: https://example.com/denied-regex
+- access: allow
+  host: github.com
+  name: AllowedOwner
+  reference: https://example.com/allowed-owner
+`)
+	var accessList []accessDataType
+	require.Nil(t, yaml.Unmarshal(rawAccessList, &accessList))
+	require.Nil(t, compileAccessPatterns(accessList))
+
+	testTables := []struct {
+		testName          string
+		normalizedURL     string
+		expectedFound     assert.BoolAssertionFunc
+		expectedAccess    accessType
+		expectedReference string
+	}{
+		{"No entry", "https://github.com/foo/bar.git", assert.False, "", ""},
+		{"Owner", "https://github.com/DeniedOwner/bar.git", assert.True, Deny, "https://example.com/denied-owner"},
+		{"Repository overrides owner", "https://github.com/DeniedOwner/AllowedRepository.git", assert.True, Allow, "https://example.com/allowed-repository"},
+		{"Repository overrides pattern", "https://github.com/SomeOwner/DeniedRepository.git", assert.True, Deny, "https://example.com/denied-repository"},
+		{"Pattern", "https://github.com/foo/bar-fork.git", assert.True, Deny, "https://example.com/denied-pattern"},
+		{"Pattern overrides owner", "https://github.com/AllowedOwner/bar-fork.git", assert.True, Deny, "https://example.com/denied-pattern"},
+		{"Deny overrides allow of same specificity", "https://github.com/SomeOwner/bar-fork.git", assert.True, Deny, "https://example.com/denied-pattern"},
+		{"Allow pattern", "https://github.com/SomeOwner/bar.git", assert.True, Allow, "https://example.com/allowed-pattern"},
+		{"Regex", "https://gitlab.com/spam-42/foo/bar.git", assert.True, Deny, "https://example.com/denied-regex"},
+		{"Regex matches complete path", "https://gitlab.com/foo/spam-42/bar.git", assert.False, "", ""},
+	}
+
+	for _, testTable := range testTables {
+		normalizedURL, err := url.Parse(testTable.normalizedURL)
+		require.Nil(t, err)
+
+		accessData, found := repositoryAccessData(*normalizedURL, accessList)
+		testTable.expectedFound(t, found, testTable.testName)
+		assert.Equal(t, testTable.expectedAccess, accessData.Access, testTable.testName)
+		assert.Equal(t, testTable.expectedReference, accessData.Reference, testTable.testName)
+	}
+}
+
+func Test_compileAccessPatterns(t *testing.T) {
+	assert.NotNil(t, compileAccessPatterns([]accessDataType{{Access: Deny, Pattern: "github.com/[foo"}}), "Invalid pattern")
+	assert.NotNil(t, compileAccessPatterns([]accessDataType{{Access: Deny, Regex: "github.com/(foo"}}), "Invalid regex")
+}
+
+func TestAccessData_subject(t *testing.T) {
+	assert.Equal(t, "library repository owner `github.com/foo`", accessDataType{Host: "github.com", Name: "foo"}.subject())
+	assert.Equal(t, "library repository `github.com/foo/bar`", accessDataType{Host: "github.com", Name: "foo", Repository: "bar"}.subject())
+	assert.Equal(t, "library repositories matching `github.com/foo/*`", accessDataType{Pattern: "github.com/foo/*"}.subject())
+	assert.Equal(t, "library repositories matching `github.com/foo/.*`", accessDataType{Regex: "github.com/foo/.*"}.subject())
+}
@@ -0,0 +1,219 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arduino/go-paths-helper"
+	"gopkg.in/yaml.v3"
+)
+
+// listCheckType is the type of the identifiers for the kinds of problems found in the list file.
+type listCheckType string
+
+const (
+	// MalformedURLCheck means the line is not a valid library repository URL.
+	MalformedURLCheck listCheckType = "malformed-url"
+	// DuplicateCheck means the URL is the same as the URL on an earlier line after normalization.
+	DuplicateCheck listCheckType = "duplicate"
+	// WhitespaceCheck means the line has leading or trailing whitespace.
+	WhitespaceCheck listCheckType = "whitespace"
+	// BlankLineCheck means the line is blank.
+	BlankLineCheck listCheckType = "blank-line"
+	// FinalNewlineCheck means the file does not end in a newline.
+	FinalNewlineCheck listCheckType = "final-newline"
+	// UnsupportedHostCheck means the URL's host is not a supported Git host.
+	UnsupportedHostCheck listCheckType = "unsupported-host"
+	// DeniedOwnerCheck means the access control file denies access to the library repository.
+	DeniedOwnerCheck listCheckType = "denied-owner"
+	// SortCheck means the URL is not in alphabetical order.
+	SortCheck listCheckType = "sort"
+)
+
+// listFindingType is the type of the data for a problem found in the list file.
+type listFindingType struct {
+	Line    int           `json:"line"`    // Line number in the list file.
+	Check   listCheckType `json:"check"`   // Kind of problem.
+	URL     string        `json:"url"`     // URL on the line.
+	Message string        `json:"message"` // Description of the problem.
+}
+
+// listReportType is the type of the `check-list` report data.
+type listReportType struct {
+	File     string            `json:"file"`     // Path of the list file.
+	Findings []listFindingType `json:"findings"` // Problems found in the list file.
+}
+
+// checkListCommand implements the `check-list` subcommand, which checks the complete list file for problems, prints a
+// JSON report, and exits with status 1 if any are found.
+func checkListCommand(arguments []string) {
+	flagSet := flag.NewFlagSet("check-list", flag.ExitOnError)
+	// Path of the access control file, relative to repopath. Optional.
+	accesslistArgument := flagSet.String("accesslist", "", "")
+	repoPathArgument := flagSet.String("repopath", "", "")
+	listNameArgument := flagSet.String("listname", "", "")
+	// Whether the list URLs must be in alphabetical order.
+	sortedArgument := flagSet.Bool("sorted", false, "")
+	flagSet.Parse(arguments)
+
+	if *repoPathArgument == "" {
+		errorExit("--repopath flag is required")
+	}
+
+	if *listNameArgument == "" {
+		errorExit("--listname flag is required")
+	}
+
+	listPath := paths.New(*repoPathArgument, *listNameArgument)
+	exist, _ := listPath.ExistCheck()
+	if !exist {
+		errorExit(fmt.Sprintf("list file %s not found", listPath))
+	}
+
+	rawList, err := listPath.ReadFile()
+	if err != nil {
+		errorExit(fmt.Sprintf("Unable to read list file: %s", err))
+	}
+
+	var accessList []accessDataType
+	if *accesslistArgument != "" {
+		accesslistPath := paths.New(*repoPathArgument, *accesslistArgument)
+		exist, _ := accesslistPath.ExistCheck()
+		if !exist {
+			errorExit("Access control file not found")
+		}
+
+		rawAccessList, err := accesslistPath.ReadFile()
+		if err != nil {
+			errorExit(fmt.Sprintf("Unable to read access control file: %s", err))
+		}
+
+		err = yaml.Unmarshal(rawAccessList, &accessList)
+		if err == nil {
+			err = compileAccessPatterns(accessList)
+		}
+		if err != nil {
+			errorExit(fmt.Sprintf("Access control file has invalid format:\n\n%s", err))
+		}
+		accessList, _ = partitionAccessList(accessList, time.Now())
+	}
+
+	report := listReportType{
+		File:     *listNameArgument,
+		Findings: checkList(rawList, accessList, *sortedArgument),
+	}
+
+	var marshaledReport bytes.Buffer
+	jsonEncoder := json.NewEncoder(&marshaledReport)
+	jsonEncoder.SetEscapeHTML(false)
+	jsonEncoder.SetIndent("", "") // Single line.
+	err = jsonEncoder.Encode(report)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Print(marshaledReport.String())
+
+	if len(report.Findings) > 0 {
+		os.Exit(1)
+	}
+}
+
+// checkList returns the problems found in the list file data. Access is only checked against the entries of the access
+// list, which should contain only the entries currently in effect. If checkSorted is set, the URLs must be in
+// case-insensitive alphabetical order.
+func checkList(rawList []byte, accessList []accessDataType, checkSorted bool) []listFindingType {
+	findings := []listFindingType{}
+	addFinding := func(line int, check listCheckType, listURL string, format string, a ...interface{}) {
+		findings = append(findings, listFindingType{Line: line, Check: check, URL: listURL, Message: fmt.Sprintf(format, a...)})
+	}
+
+	lines := strings.Split(string(rawList), "\n")
+	finalNewline := lines[len(lines)-1] == ""
+	if finalNewline {
+		lines = lines[:len(lines)-1]
+	}
+
+	normalizedURLLines := make(map[string]int) // Line numbers of the normalized URLs.
+	previousURL := ""
+	previousURLLine := 0
+	for lineIndex, rawLine := range lines {
+		lineNumber := lineIndex + 1
+		listURL := strings.TrimSpace(rawLine)
+		if listURL == "" {
+			addFinding(lineNumber, BlankLineCheck, "", "Line is blank.")
+			continue
+		}
+		if listURL != rawLine {
+			addFinding(lineNumber, WhitespaceCheck, listURL, "Line has leading or trailing whitespace.")
+		}
+
+		if checkSorted {
+			if previousURL != "" && strings.ToLower(listURL) < strings.ToLower(previousURL) {
+				addFinding(lineNumber, SortCheck, listURL, "URL is not in alphabetical order. It should come before line %d.", previousURLLine)
+			}
+			previousURL = listURL
+			previousURLLine = lineNumber
+		}
+
+		listURLObject, err := url.Parse(listURL)
+		if err != nil || (listURLObject.Scheme != "https" && listURLObject.Scheme != "http") || listURLObject.Host == "" {
+			addFinding(lineNumber, MalformedURLCheck, listURL, "Line is not a valid HTTP(S) URL.")
+			continue
+		}
+
+		gitHost, supportedGitHost := gitHostProviderForURL(listURLObject)
+		normalizedURLObject := gitHost.NormalizeURL(listURLObject)
+		if _, _, err := gitHost.OwnerAndRepository(normalizedURLObject); err != nil {
+			addFinding(lineNumber, MalformedURLCheck, listURL, "URL is not a library repository URL: %s", err)
+			continue
+		}
+		if !supportedGitHost {
+			addFinding(lineNumber, UnsupportedHostCheck, listURL, "`%s` is not a supported Git host.", normalizedURLObject.Host)
+		}
+
+		normalizedURL := normalizedURLObject.String()
+		if firstLine, duplicate := normalizedURLLines[normalizedURL]; duplicate {
+			addFinding(lineNumber, DuplicateCheck, listURL, "URL is a duplicate of line %d.", firstLine)
+		} else {
+			normalizedURLLines[normalizedURL] = lineNumber
+		}
+
+		accessData, found := repositoryAccessData(normalizedURLObject, accessList)
+		if found && accessData.Access == Deny {
+			addFinding(lineNumber, DeniedOwnerCheck, listURL, "Library registry privileges for %s have been revoked. See: %s", accessData.subject(), accessData.Reference)
+		}
+	}
+
+	if !finalNewline {
+		addFinding(len(lines), FinalNewlineCheck, "", "File does not end in a newline.")
+	}
+
+	return findings
+}
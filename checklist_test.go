@@ -0,0 +1,108 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_checkList(t *testing.T) {
+	accessList := []accessDataType{
+		{Access: Deny, Host: "github.com", Name: "denied", Reference: "https://example.com"},
+	}
+
+	testTables := []struct {
+		testName         string
+		rawList          string
+		checkSorted      bool
+		expectedFindings []listFindingType
+	}{
+		{
+			testName:         "Valid",
+			rawList:          "https://github.com/foo/bar\nhttps://gitlab.com/foo/baz/qux\n",
+			expectedFindings: []listFindingType{},
+		},
+		{
+			testName:         "Empty",
+			rawList:          "",
+			expectedFindings: []listFindingType{},
+		},
+		{
+			testName: "Format",
+			rawList:  "https://github.com/foo/bar \n\nhttps://github.com/foo/baz",
+			expectedFindings: []listFindingType{
+				{Line: 1, Check: WhitespaceCheck, URL: "https://github.com/foo/bar", Message: "Line has leading or trailing whitespace."},
+				{Line: 2, Check: BlankLineCheck, Message: "Line is blank."},
+				{Line: 3, Check: FinalNewlineCheck, Message: "File does not end in a newline."},
+			},
+		},
+		{
+			testName: "Malformed URL",
+			rawList:  "foo\nhttps://github.com/foo\nhttps://github.com/foo/bar%zz\n",
+			expectedFindings: []listFindingType{
+				{Line: 1, Check: MalformedURLCheck, URL: "foo", Message: "Line is not a valid HTTP(S) URL."},
+				{Line: 2, Check: MalformedURLCheck, URL: "https://github.com/foo", Message: "URL is not a library repository URL: URL https://github.com/foo.git is not in the format github.com/<owner>/<repository>"},
+				{Line: 3, Check: MalformedURLCheck, URL: "https://github.com/foo/bar%zz", Message: "Line is not a valid HTTP(S) URL."},
+			},
+		},
+		{
+			testName: "Duplicate",
+			rawList:  "https://github.com/foo/bar\nhttps://github.com/foo/baz\nhttp://github.com/foo/bar.git/\n",
+			expectedFindings: []listFindingType{
+				{Line: 3, Check: DuplicateCheck, URL: "http://github.com/foo/bar.git/", Message: "URL is a duplicate of line 1."},
+			},
+		},
+		{
+			testName: "Unsupported host",
+			rawList:  "https://example.com/foo/bar\n",
+			expectedFindings: []listFindingType{
+				{Line: 1, Check: UnsupportedHostCheck, URL: "https://example.com/foo/bar", Message: "`example.com` is not a supported Git host."},
+			},
+		},
+		{
+			testName: "Denied owner",
+			rawList:  "https://github.com/foo/bar\nhttps://github.com/denied/bar\n",
+			expectedFindings: []listFindingType{
+				{Line: 2, Check: DeniedOwnerCheck, URL: "https://github.com/denied/bar", Message: "Library registry privileges for library repository owner `github.com/denied` have been revoked. See: https://example.com"},
+			},
+		},
+		{
+			testName:         "Unsorted, not checked",
+			rawList:          "https://github.com/foo/baz\nhttps://github.com/foo/bar\n",
+			expectedFindings: []listFindingType{},
+		},
+		{
+			testName:    "Unsorted",
+			rawList:     "https://github.com/foo/Bar\nhttps://github.com/foo/baz\nhttps://github.com/foo/bar\n",
+			checkSorted: true,
+			expectedFindings: []listFindingType{
+				{Line: 3, Check: SortCheck, URL: "https://github.com/foo/bar", Message: "URL is not in alphabetical order. It should come before line 2."},
+			},
+		},
+	}
+
+	for _, testTable := range testTables {
+		assert.Equal(t, testTable.expectedFindings, checkList([]byte(testTable.rawList), accessList, testTable.checkSorted), testTable.testName)
+	}
+}
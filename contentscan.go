@@ -0,0 +1,240 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/arduino/go-paths-helper"
+)
+
+// contentCheckType is the type of the identifier of a repository content check.
+type contentCheckType string
+
+const (
+	// ExecutableContentCheck flags files in an executable format.
+	ExecutableContentCheck contentCheckType = "executable"
+	// BinaryContentCheck flags files with binary content that are not in a common image or document format.
+	BinaryContentCheck contentCheckType = "binary"
+	// OversizedContentCheck flags files larger than maxContentFileSize.
+	OversizedContentCheck contentCheckType = "oversized"
+	// SubmoduleContentCheck flags Git submodules, which are not fetched by the Library Manager indexer.
+	SubmoduleContentCheck contentCheckType = "submodule"
+	// SymlinkContentCheck flags symbolic links that point outside the repository.
+	SymlinkContentCheck contentCheckType = "symlink"
+	// DevelopmentContentCheck flags the `.development` marker file, which makes the library unsuitable for release.
+	DevelopmentContentCheck contentCheckType = "development"
+)
+
+// contentFindingType is the type of the data for a problem found in the content of the submission repository.
+type contentFindingType struct {
+	Path    string           `json:"path"`    // Slash-separated path of the file, relative to the repository root.
+	Check   contentCheckType `json:"check"`   // Identifier of the check that found the problem.
+	Message string           `json:"message"` // Description of the problem.
+}
+
+// maxContentFileSize is the size in bytes above which a repository file is flagged.
+var maxContentFileSize int64 = 10 * 1024 * 1024
+
+// binaryDetectionSize is the number of bytes at the start of a file that are checked for binary content. This is the
+// same as Git's heuristic.
+const binaryDetectionSize = 8000
+
+// executableSignatures are the magic numbers of executable file formats, indexed by format name. Formats whose magic
+// numbers are also found in other files (PE and universal Mach-O) are identified by executableFormat instead.
+var executableSignatures = map[string][][]byte{
+	"ELF":    {[]byte("\x7fELF")},
+	"Mach-O": {[]byte("\xfe\xed\xfa\xce"), []byte("\xfe\xed\xfa\xcf"), []byte("\xce\xfa\xed\xfe"), []byte("\xcf\xfa\xed\xfe")},
+	"script": {[]byte("#!")},
+}
+
+// maxUniversalMachOArchitectures is the number of architectures below which a file with the universal Mach-O magic
+// number is identified as a universal binary. Java class files have the same magic number, followed by their version,
+// which is higher. This is the same as file(1)'s heuristic.
+const maxUniversalMachOArchitectures = 20
+
+// binaryFileExtensions are the extensions of the binary file formats commonly used for library documentation and
+// assets, which are not flagged.
+var binaryFileExtensions = []string{".bmp", ".gif", ".ico", ".jpeg", ".jpg", ".pdf", ".png", ".webp"}
+
+// submodulePathRegexp matches the `path` key of a submodule in the .gitmodules file.
+var submodulePathRegexp = regexp.MustCompile(`(?m)^\s*path\s*=\s*(.+?)\s*$`)
+
+// scanContent checks the content of the repository checked out at the path.
+func scanContent(clonePath *paths.Path) ([]contentFindingType, error) {
+	var findings []contentFindingType
+	addFinding := func(filePath string, check contentCheckType, format string, a ...interface{}) {
+		findings = append(findings, contentFindingType{Path: filePath, Check: check, Message: fmt.Sprintf(format, a...)})
+	}
+
+	root := clonePath.String()
+	err := filepath.WalkDir(root, func(walkPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(root, walkPath)
+		if err != nil {
+			return err
+		}
+		slashPath := filepath.ToSlash(relativePath)
+
+		if entry.IsDir() {
+			if slashPath == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.Type()&fs.ModeSymlink != 0 {
+			if !symlinkIsInside(root, walkPath) {
+				target, _ := os.Readlink(walkPath)
+				addFinding(slashPath, SymlinkContentCheck, "Symbolic link points outside the repository, to `%s`.", target)
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		switch slashPath {
+		case ".development":
+			addFinding(slashPath, DevelopmentContentCheck, "The `.development` file marks the library as under development. Library Manager does not accept releases that contain it.")
+		case ".gitmodules":
+			rawGitModules, err := os.ReadFile(walkPath)
+			if err != nil {
+				return err
+			}
+			for _, match := range submodulePathRegexp.FindAllSubmatch(rawGitModules, -1) {
+				addFinding(string(match[1]), SubmoduleContentCheck, "Submodule `%s` will not be included in the release, since the Library Manager indexer does not fetch submodules.", match[1])
+			}
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() > maxContentFileSize {
+			addFinding(slashPath, OversizedContentCheck, "File size %d bytes exceeds the limit of %d bytes.", info.Size(), maxContentFileSize)
+		}
+
+		header, err := readFileHeader(walkPath)
+		if err != nil {
+			return err
+		}
+		// Scripts are only flagged if they have the executable permission, since shebang lines are also used in text files.
+		if format := executableFormat(header); format != "" && (format != "script" || info.Mode()&0111 != 0) {
+			addFinding(slashPath, ExecutableContentCheck, "File is an executable (%s).", format)
+		} else if bytes.IndexByte(header, 0) >= 0 && !slices.Contains(binaryFileExtensions, strings.ToLower(filepath.Ext(walkPath))) {
+			addFinding(slashPath, BinaryContentCheck, "File has binary content.")
+		}
+
+		return nil
+	})
+
+	return findings, err
+}
+
+// readFileHeader returns the bytes at the start of the file that are used to identify its format.
+func readFileHeader(filePath string) ([]byte, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	header := make([]byte, binaryDetectionSize)
+	count, err := io.ReadFull(file, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+
+	return header[:count], nil
+}
+
+// executableFormat returns the name of the executable format of the file with the header, or an empty string if it is
+// not an executable. Scripts are identified by their shebang line.
+func executableFormat(header []byte) string {
+	for format, signatures := range executableSignatures {
+		for _, signature := range signatures {
+			if bytes.HasPrefix(header, signature) {
+				return format
+			}
+		}
+	}
+
+	if isPEHeader(header) {
+		return "PE"
+	}
+	// Universal binaries start with the magic number and the big-endian number of architectures.
+	if bytes.HasPrefix(header, []byte("\xca\xfe\xba\xbe")) && len(header) >= 8 {
+		if architectures := binary.BigEndian.Uint32(header[4:8]); architectures > 0 && architectures < maxUniversalMachOArchitectures {
+			return "Mach-O"
+		}
+	}
+
+	return ""
+}
+
+// isPEHeader returns whether the header is that of a PE executable: an MS-DOS header, starting with `MZ`, whose
+// little-endian e_lfanew field at offset 0x3C is the offset of the `PE\0\0` signature.
+func isPEHeader(header []byte) bool {
+	if !bytes.HasPrefix(header, []byte("MZ")) || len(header) < 0x40 {
+		return false
+	}
+	signatureOffset := uint64(binary.LittleEndian.Uint32(header[0x3c:0x40]))
+
+	return signatureOffset+4 <= uint64(len(header)) && bytes.Equal(header[signatureOffset:signatureOffset+4], []byte("PE\x00\x00"))
+}
+
+// symlinkIsInside returns whether the target of the symbolic link at the path is inside the root folder.
+func symlinkIsInside(root string, linkPath string) bool {
+	target, err := os.Readlink(linkPath)
+	if err != nil || filepath.IsAbs(target) {
+		return false
+	}
+	if !pathIsInside(root, filepath.Join(filepath.Dir(linkPath), target)) {
+		return false
+	}
+
+	// The target may itself be a link, to a location outside the root folder.
+	if resolvedTarget, err := filepath.EvalSymlinks(linkPath); err == nil {
+		resolvedRoot, err := filepath.EvalSymlinks(root)
+		return err == nil && pathIsInside(resolvedRoot, resolvedTarget)
+	}
+
+	return true
+}
+
+// pathIsInside returns whether the path is inside the root folder, based on the path strings.
+func pathIsInside(root string, path string) bool {
+	relativePath, err := filepath.Rel(root, path)
+	return err == nil && relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/arduino/go-paths-helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_scanContent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Symbolic links and permissions are not supported on Windows")
+	}
+
+	originalMaxContentFileSize := maxContentFileSize
+	defer func() { maxContentFileSize = originalMaxContentFileSize }()
+	maxContentFileSize = 100
+
+	outsidePath, err := paths.MkTempDir("", "")
+	require.Nil(t, err)
+	defer outsidePath.RemoveAll()
+
+	clonePath, err := paths.MkTempDir("", "")
+	require.Nil(t, err)
+	defer clonePath.RemoveAll()
+
+	files := map[string]string{
+		"library.properties":    "name=Foo\nversion=1.0.0\n",
+		"src/Foo.h":             "#pragma once\n",
+		"extras/image.png":      "\x89PNG\r\n\x1a\n\x00",
+		"extras/data.bin":       "\x01\x00\x02",
+		"extras/tool.exe":       "MZ\x90\x00" + strings.Repeat("\x00", 0x38) + "\x40\x00\x00\x00PE\x00\x00",
+		"extras/MZ-80.md":       "MZ-80 is a home computer.\n" + strings.Repeat("-", 0x40) + "\n",
+		"extras/tool":           "\x7fELF\x02\x01",
+		"extras/script.sh":      "#!/bin/sh\n",
+		"extras/example.sh":     "#!/bin/sh\n",
+		"extras/large.txt":      string(make([]byte, 101)),
+		".development":          "",
+		"examples/.development": "",
+		".gitmodules":           "[submodule \"extras/lib\"]\n\tpath = extras/lib\n\turl = https://github.com/foo/lib.git\n",
+		".git/objects/foo":      "\x00",
+	}
+	for filePath, content := range files {
+		require.Nil(t, clonePath.Join(filePath).Parent().MkdirAll())
+		require.Nil(t, clonePath.Join(filePath).WriteFile([]byte(content)))
+	}
+	require.Nil(t, clonePath.Join("extras", "script.sh").Chmod(0755))
+	require.Nil(t, os.Symlink("../src/Foo.h", clonePath.Join("extras", "inside").String()))
+	require.Nil(t, os.Symlink("../../"+outsidePath.Base(), clonePath.Join("extras", "relative-outside").String()))
+	require.Nil(t, os.Symlink(outsidePath.String(), clonePath.Join("extras", "absolute-outside").String()))
+	require.Nil(t, os.Symlink(outsidePath.String(), clonePath.Join("outside").String()))
+	require.Nil(t, os.Symlink("../outside", clonePath.Join("extras", "indirect-outside").String()))
+
+	findings, err := scanContent(clonePath)
+	require.Nil(t, err)
+	assert.ElementsMatch(
+		t,
+		[]contentFindingType{
+			{Path: ".development", Check: DevelopmentContentCheck, Message: "The `.development` file marks the library as under development. Library Manager does not accept releases that contain it."},
+			{Path: "extras/lib", Check: SubmoduleContentCheck, Message: "Submodule `extras/lib` will not be included in the release, since the Library Manager indexer does not fetch submodules."},
+			{Path: "extras/absolute-outside", Check: SymlinkContentCheck, Message: "Symbolic link points outside the repository, to `" + outsidePath.String() + "`."},
+			{Path: "extras/data.bin", Check: BinaryContentCheck, Message: "File has binary content."},
+			{Path: "extras/indirect-outside", Check: SymlinkContentCheck, Message: "Symbolic link points outside the repository, to `../outside`."},
+			{Path: "extras/large.txt", Check: OversizedContentCheck, Message: "File size 101 bytes exceeds the limit of 100 bytes."},
+			{Path: "extras/large.txt", Check: BinaryContentCheck, Message: "File has binary content."},
+			{Path: "extras/relative-outside", Check: SymlinkContentCheck, Message: "Symbolic link points outside the repository, to `../../" + outsidePath.Base() + "`."},
+			{Path: "extras/script.sh", Check: ExecutableContentCheck, Message: "File is an executable (script)."},
+			{Path: "extras/tool", Check: ExecutableContentCheck, Message: "File is an executable (ELF)."},
+			{Path: "extras/tool.exe", Check: ExecutableContentCheck, Message: "File is an executable (PE)."},
+			{Path: "outside", Check: SymlinkContentCheck, Message: "Symbolic link points outside the repository, to `" + outsidePath.String() + "`."},
+		},
+		findings,
+	)
+
+	emptyPath, err := paths.MkTempDir("", "")
+	require.Nil(t, err)
+	defer emptyPath.RemoveAll()
+	findings, err = scanContent(emptyPath)
+	require.Nil(t, err)
+	assert.Nil(t, findings, "No findings")
+}
+
+func Test_executableFormat(t *testing.T) {
+	testTables := []struct {
+		header         string
+		expectedFormat string
+	}{
+		{"\x7fELF\x02", "ELF"},
+		{"MZ\x90\x00" + strings.Repeat("\x00", 0x38) + "\x40\x00\x00\x00PE\x00\x00", "PE"},
+		{"MZ-80 is a home computer." + strings.Repeat(" ", 0x40), ""},
+		{"MZ\x90\x00" + strings.Repeat("\x00", 0x38) + "\x00\x10\x00\x00", ""},
+		{"MZ\x90\x00", ""},
+		{"\xcf\xfa\xed\xfe", "Mach-O"},
+		{"\xca\xfe\xba\xbe\x00\x00\x00\x02", "Mach-O"},
+		{"\xca\xfe\xba\xbe\x00\x00\x00\x34", ""},
+		{"#!/usr/bin/env python", "script"},
+		{"#include <Arduino.h>", ""},
+		{"", ""},
+	}
+
+	for _, testTable := range testTables {
+		assert.Equal(t, testTable.expectedFormat, executableFormat([]byte(testTable.header)), testTable.header)
+	}
+}
+
+func Test_populateSubmissionContentScan(t *testing.T) {
+	withTestRepository(t, map[string]map[string]string{"1.0.0": {"library.properties": "name=Foo\nversion=1.0.0\n", ".development": ""}})
+
+	submission, _, err := populateTestSubmission(listChangeType{URL: "https://github.com/foo/bar"}, submissionOptionsType{})
+	require.Nil(t, err)
+	require.Len(t, submission.ContentFindings, 1, "Content scan")
+	assert.Equal(t, DevelopmentContentCheck, submission.ContentFindings[0].Check, "Content scan")
+}
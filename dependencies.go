@@ -0,0 +1,139 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dependencyProblemType is the type of the identifiers for the kinds of problems with library dependencies.
+type dependencyProblemType string
+
+const (
+	// UnknownDependencyProblem means there is no library with the dependency name in the index.
+	UnknownDependencyProblem dependencyProblemType = "unknown"
+	// UnsatisfiedDependencyProblem means none of the releases of the dependency satisfy the version constraint.
+	UnsatisfiedDependencyProblem dependencyProblemType = "unsatisfied"
+	// CyclicDependencyProblem means the dependency depends on the library, directly or indirectly.
+	CyclicDependencyProblem dependencyProblemType = "cyclic"
+)
+
+// dependencyFindingType is the type of the data for a problem found with a library dependency.
+type dependencyFindingType struct {
+	Dependency string                `json:"dependency"` // Name of the library dependency.
+	Problem    dependencyProblemType `json:"problem"`    // Kind of problem.
+	Message    string                `json:"message"`    // Description of the problem.
+}
+
+// resolveDependencies resolves the dependencies of the library against the index and returns the problems found.
+func resolveDependencies(libraryName string, dependencies []dependencyType, libraryIndex *libraryIndexType) []dependencyFindingType {
+	var findings []dependencyFindingType
+	for _, dependency := range dependencies {
+		if dependency.Name == libraryName {
+			findings = append(findings, dependencyFindingType{Dependency: dependency.Name, Problem: CyclicDependencyProblem, Message: "The library depends on itself."})
+			continue
+		}
+
+		releases := libraryIndex.releasesForName(dependency.Name)
+		if len(releases) == 0 {
+			findings = append(findings, dependencyFindingType{Dependency: dependency.Name, Problem: UnknownDependencyProblem, Message: fmt.Sprintf("Library `%s` is not in Library Manager.", dependency.Name)})
+			continue
+		}
+
+		release, found := resolveDependency(releases, dependency.Constraint)
+		if !found {
+			findings = append(findings, dependencyFindingType{Dependency: dependency.Name, Problem: UnsatisfiedDependencyProblem, Message: fmt.Sprintf("No release of library `%s` satisfies the version constraint.", dependency.Name)})
+			continue
+		}
+
+		if cycle := dependencyCycle(libraryName, []string{libraryName, dependency.Name}, release, libraryIndex, make(map[string]bool)); cycle != nil {
+			findings = append(findings, dependencyFindingType{Dependency: dependency.Name, Problem: CyclicDependencyProblem, Message: fmt.Sprintf("Dependency cycle: %s.", strings.Join(cycle, " → "))})
+		}
+	}
+
+	return findings
+}
+
+// resolveDependency returns the release with the highest version that satisfies the constraint, and whether there is
+// one. Any release satisfies a nil constraint. When none of the releases has a valid version (e.g., from the index
+// source file, which doesn't have versions), the constraint can't be checked, so the first release is returned.
+func resolveDependency(releases []libraryIndexReleaseType, constraint versionConstraint) (libraryIndexReleaseType, bool) {
+	var resolvedRelease libraryIndexReleaseType
+	var resolvedVersion versionType
+	found := false
+	versioned := false
+	for _, release := range releases {
+		version, err := parseVersion(release.Version)
+		if err != nil {
+			continue
+		}
+		versioned = true
+		if constraint != nil && !constraint.match(version) {
+			continue
+		}
+		if !found || version.compare(resolvedVersion) > 0 {
+			resolvedRelease = release
+			resolvedVersion = version
+			found = true
+		}
+	}
+	if !versioned && len(releases) > 0 {
+		return releases[0], true
+	}
+
+	return resolvedRelease, found
+}
+
+// dependencyCycle returns the chain of library names from the library back to itself through the dependencies of the
+// release, or nil if there is no such cycle. path is the chain of library names leading to the release.
+func dependencyCycle(libraryName string, path []string, release libraryIndexReleaseType, libraryIndex *libraryIndexType, visited map[string]bool) []string {
+	if visited[release.Name] {
+		return nil
+	}
+	visited[release.Name] = true
+
+	for _, dependency := range release.Dependencies {
+		dependencyPath := append(append([]string{}, path...), dependency.Name)
+		if dependency.Name == libraryName {
+			return dependencyPath
+		}
+
+		var constraint versionConstraint
+		if dependency.Version != "" {
+			var err error
+			constraint, err = parseVersionConstraint(dependency.Version)
+			if err != nil {
+				continue // The index data is not validated here, so the dependency is ignored.
+			}
+		}
+		dependencyRelease, found := resolveDependency(libraryIndex.releasesForName(dependency.Name), constraint)
+		if !found {
+			continue
+		}
+		if cycle := dependencyCycle(libraryName, dependencyPath, dependencyRelease, libraryIndex, visited); cycle != nil {
+			return cycle
+		}
+	}
+
+	return nil
+}
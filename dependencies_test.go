@@ -0,0 +1,119 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_resolveDependencies(t *testing.T) {
+	libraryIndex, err := parseLibraryIndex([]byte(`{"libraries": [
+		{"name": "Servo", "version": "1.0.0", "repository": "https://github.com/arduino-libraries/Servo.git"},
+		{"name": "Servo", "version": "1.2.0", "repository": "https://github.com/arduino-libraries/Servo.git"},
+		{"name": "Bar", "version": "1.0.0", "repository": "https://github.com/foo/bar.git", "dependencies": [{"name": "Baz"}]},
+		{"name": "Baz", "version": "1.0.0", "repository": "https://github.com/foo/baz.git", "dependencies": [{"name": "Foo", "version": ">=1.0.0"}]},
+		{"name": "Qux", "version": "1.0.0", "repository": "https://github.com/foo/qux.git", "dependencies": [{"name": "Foo"}]},
+		{"name": "Qux", "version": "2.0.0", "repository": "https://github.com/foo/qux.git", "dependencies": [{"name": "Servo"}]}
+	]}`))
+	require.Nil(t, err)
+
+	testTables := []struct {
+		testName         string
+		depends          string
+		expectedFindings []dependencyFindingType
+	}{
+		{"Resolved", "Servo, Qux (>=2.0.0)", nil},
+		{"Resolved to latest release", "Qux", nil},
+		{"Constraint", "Servo (>=1.1.0 && <2.0.0)", nil},
+		{
+			"Unknown",
+			"Nonexistent, servo",
+			[]dependencyFindingType{
+				{Dependency: "Nonexistent", Problem: UnknownDependencyProblem, Message: "Library `Nonexistent` is not in Library Manager."},
+				{Dependency: "servo", Problem: UnknownDependencyProblem, Message: "Library `servo` is not in Library Manager."},
+			},
+		},
+		{
+			"Unsatisfied",
+			"Servo (>=2.0.0)",
+			[]dependencyFindingType{{Dependency: "Servo", Problem: UnsatisfiedDependencyProblem, Message: "No release of library `Servo` satisfies the version constraint."}},
+		},
+		{
+			"Self",
+			"Foo",
+			[]dependencyFindingType{{Dependency: "Foo", Problem: CyclicDependencyProblem, Message: "The library depends on itself."}},
+		},
+		{
+			"Cycle",
+			"Bar",
+			[]dependencyFindingType{{Dependency: "Bar", Problem: CyclicDependencyProblem, Message: "Dependency cycle: Foo → Bar → Baz → Foo."}},
+		},
+		{
+			"Cycle through constrained release",
+			"Qux (<2.0.0)",
+			[]dependencyFindingType{{Dependency: "Qux", Problem: CyclicDependencyProblem, Message: "Dependency cycle: Foo → Qux → Foo."}},
+		},
+	}
+
+	for _, testTable := range testTables {
+		dependencies, err := parseDepends(testTable.depends)
+		require.Nil(t, err, testTable.testName)
+		assert.Equal(t, testTable.expectedFindings, resolveDependencies("Foo", dependencies, libraryIndex), testTable.testName)
+	}
+
+	libraryIndex, err = parseLibraryIndex([]byte("https://github.com/arduino-libraries/Servo|Arduino|Servo\nhttps://github.com/foo/bar|Contributed|Bar\n"))
+	require.Nil(t, err)
+	dependencies, err := parseDepends("Servo (>=1.0.0), Bar (<1.0.0)")
+	require.Nil(t, err)
+	assert.Nil(t, resolveDependencies("Foo", dependencies, libraryIndex), "Index source file has no versions to check the constraints")
+}
+
+func Test_resolveDependency(t *testing.T) {
+	releases := []libraryIndexReleaseType{{Name: "Servo", Version: "1.0.0"}, {Name: "Servo", Version: "1.2.0-rc1"}, {Name: "Servo", Version: "1.1.0"}}
+
+	release, found := resolveDependency(releases, nil)
+	assert.True(t, found)
+	assert.Equal(t, "1.2.0-rc1", release.Version, "Highest version")
+
+	constraint, err := parseVersionConstraint("<1.1.0")
+	require.Nil(t, err)
+	release, found = resolveDependency(releases, constraint)
+	assert.True(t, found)
+	assert.Equal(t, "1.0.0", release.Version)
+
+	constraint, err = parseVersionConstraint(">=2.0.0")
+	require.Nil(t, err)
+	_, found = resolveDependency(releases, constraint)
+	assert.False(t, found)
+
+	release, found = resolveDependency([]libraryIndexReleaseType{{Name: "Servo"}}, nil)
+	assert.True(t, found, "Release without version")
+	assert.Equal(t, "Servo", release.Name)
+	release, found = resolveDependency([]libraryIndexReleaseType{{Name: "Servo"}}, constraint)
+	assert.True(t, found, "Constraint is not checked without versions")
+	assert.Equal(t, "Servo", release.Name)
+	_, found = resolveDependency([]libraryIndexReleaseType{{Name: "Servo"}, {Name: "Servo", Version: "1.0.0"}}, constraint)
+	assert.False(t, found, "Constraint is checked against the versioned releases")
+}
@@ -0,0 +1,303 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arduino/go-paths-helper"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"gopkg.in/yaml.v3"
+)
+
+// The fixtures folder, used for offline operation, has this structure:
+//
+//	http.yml                                   Canned responses for HTTP requests.
+//	repositories.yml                           Descriptions of repositories used in place of the remote repositories.
+//	repositories/<host>/<path>.git             Bare repositories used in place of the remote repositories.
+//
+// Remote repository URLs are rewritten to the corresponding local repository path, so a repository URL like
+// `https://github.com/foo/bar.git` is served from `repositories/github.com/foo/bar.git`. The repositories are copied
+// to a temporary folder, where the repositories described in repositories.yml are also created, so the fixtures folder
+// is never modified.
+
+// httpFixtureType is the type of the data for a canned HTTP response.
+type httpFixtureType struct {
+	URL      string `yaml:"url"`      // Request URL.
+	Status   int    `yaml:"status"`   // Response status code. Defaults to 200, or 301 if Location is set.
+	Location string `yaml:"location"` // Redirect target URL.
+	Body     string `yaml:"body"`     // Response body.
+}
+
+// repositoryFixtureType is the type of the data for a repository description in repositories.yml.
+type repositoryFixtureType struct {
+	URL     string              `yaml:"url"`     // Remote repository URL.
+	Commits []commitFixtureType `yaml:"commits"` // Commits of the default branch, from oldest to newest.
+}
+
+// commitFixtureType is the type of the data for a commit of a repository description.
+type commitFixtureType struct {
+	Tag   string            `yaml:"tag"`   // Name of the tag of the commit. Optional.
+	Files map[string]string `yaml:"files"` // Content of each file of the commit, indexed by slash-separated path.
+}
+
+// fixturesType is the type of the data for offline operation.
+type fixturesType struct {
+	httpFixtures    map[string]httpFixtureType // Canned HTTP responses, indexed by URL.
+	temporaryPath   *paths.Path                // Temporary folder containing the local bare repositories.
+	repositoriesURL string                     // file:// URL of the folder containing the local bare repositories.
+}
+
+// loadFixtures loads the fixtures from the folder at the path. The temporary folder of the fixtures must be removed
+// by calling remove when they are no longer used.
+func loadFixtures(fixturesPath *paths.Path) (*fixturesType, error) {
+	if isDir, _ := fixturesPath.IsDirCheck(); !isDir {
+		return nil, fmt.Errorf("Fixtures folder %s not found", fixturesPath)
+	}
+
+	fixtures := fixturesType{httpFixtures: make(map[string]httpFixtureType)}
+
+	httpFixturesPath := fixturesPath.Join("http.yml")
+	if exist, _ := httpFixturesPath.ExistCheck(); exist {
+		rawHTTPFixtures, err := httpFixturesPath.ReadFile()
+		if err != nil {
+			return nil, err
+		}
+		var httpFixtures []httpFixtureType
+		err = yaml.Unmarshal(rawHTTPFixtures, &httpFixtures)
+		if err != nil {
+			return nil, fmt.Errorf("HTTP fixtures file has invalid format: %s", err)
+		}
+		for _, httpFixture := range httpFixtures {
+			if httpFixture.Status == 0 {
+				httpFixture.Status = http.StatusOK
+				if httpFixture.Location != "" {
+					httpFixture.Status = http.StatusMovedPermanently
+				}
+			}
+			fixtures.httpFixtures[httpFixture.URL] = httpFixture
+		}
+	}
+
+	var repositoryFixtures []repositoryFixtureType
+	repositoryFixturesPath := fixturesPath.Join("repositories.yml")
+	if exist, _ := repositoryFixturesPath.ExistCheck(); exist {
+		rawRepositoryFixtures, err := repositoryFixturesPath.ReadFile()
+		if err != nil {
+			return nil, err
+		}
+		err = yaml.Unmarshal(rawRepositoryFixtures, &repositoryFixtures)
+		if err != nil {
+			return nil, fmt.Errorf("Repository fixtures file has invalid format: %s", err)
+		}
+	}
+
+	var err error
+	fixtures.temporaryPath, err = paths.MkTempDir("", "")
+	if err != nil {
+		return nil, err
+	}
+	repositoriesPath := fixtures.temporaryPath.Join("repositories")
+	if isDir, _ := fixturesPath.Join("repositories").IsDirCheck(); isDir {
+		err = fixturesPath.Join("repositories").CopyDirTo(repositoriesPath)
+	} else {
+		err = repositoriesPath.Mkdir()
+	}
+	if err != nil {
+		fixtures.remove()
+		return nil, err
+	}
+	for _, repositoryFixture := range repositoryFixtures {
+		err = createFixtureRepository(repositoriesPath, repositoryFixture)
+		if err != nil {
+			fixtures.remove()
+			return nil, fmt.Errorf("Unable to create repository %s: %s", repositoryFixture.URL, err)
+		}
+	}
+
+	absoluteRepositoriesPath, err := repositoriesPath.Abs()
+	if err != nil {
+		fixtures.remove()
+		return nil, err
+	}
+	repositoriesURLPath := filepath.ToSlash(absoluteRepositoriesPath.String())
+	if !strings.HasPrefix(repositoriesURLPath, "/") {
+		repositoriesURLPath = "/" + repositoriesURLPath // Windows drive letter path.
+	}
+	fixtures.repositoriesURL = "file://" + repositoriesURLPath + "/"
+
+	return &fixtures, nil
+}
+
+// remove removes the temporary folder of the fixtures.
+func (fixtures *fixturesType) remove() {
+	fixtures.temporaryPath.RemoveAll()
+}
+
+// createFixtureRepository creates the bare repository for the description in the repositories folder.
+func createFixtureRepository(repositoriesPath *paths.Path, repositoryFixture repositoryFixtureType) error {
+	repositoryURL, err := url.Parse(repositoryFixture.URL)
+	if err != nil {
+		return err
+	}
+	if repositoryURL.Host == "" {
+		return fmt.Errorf("URL has no host")
+	}
+	repositoryPath := repositoriesPath.Join(repositoryURL.Host, filepath.FromSlash(strings.TrimSuffix(repositoryURL.Path, ".git")+".git"))
+	if exist, _ := repositoryPath.ExistCheck(); exist {
+		return fmt.Errorf("Repository is defined multiple times")
+	}
+
+	// The commits are made in an in-memory worktree, so only the Git data is written to the repository folder.
+	storage := filesystem.NewStorage(osfs.New(repositoryPath.String()), cache.NewObjectLRUDefault())
+	repository, err := git.Init(storage, memfs.New())
+	if err != nil {
+		return err
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return err
+	}
+
+	// Commit times are fixed, so the repositories are always the same.
+	commitTime := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var previousFilePaths []string
+	for commitIndex, commitFixture := range repositoryFixture.Commits {
+		for _, filePath := range previousFilePaths {
+			if err := worktree.Filesystem.Remove(filePath); err != nil {
+				return err
+			}
+		}
+		previousFilePaths = nil
+		for filePath, content := range commitFixture.Files {
+			if path.IsAbs(filePath) || strings.HasPrefix(path.Clean(filePath), "..") {
+				return fmt.Errorf("File path %s is outside the repository", filePath)
+			}
+			file, err := worktree.Filesystem.Create(filePath)
+			if err != nil {
+				return err
+			}
+			_, err = file.Write([]byte(content))
+			file.Close()
+			if err != nil {
+				return err
+			}
+			previousFilePaths = append(previousFilePaths, filePath)
+		}
+
+		err = worktree.AddWithOptions(&git.AddOptions{All: true})
+		if err != nil {
+			return err
+		}
+		signature := object.Signature{Name: "Fixtures", Email: "fixtures@example.com", When: commitTime.Add(time.Duration(commitIndex) * time.Hour)}
+		commitHash, err := worktree.Commit(fmt.Sprintf("Commit %d", commitIndex+1), &git.CommitOptions{Author: &signature, AllowEmptyCommits: true})
+		if err != nil {
+			return err
+		}
+		if commitFixture.Tag != "" {
+			_, err = repository.CreateTag(commitFixture.Tag, commitHash, nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Remove the in-memory worktree from the configuration.
+	repositoryConfig, err := repository.Config()
+	if err != nil {
+		return err
+	}
+	repositoryConfig.Core.IsBare = true
+	repositoryConfig.Core.Worktree = ""
+
+	return repository.SetConfig(repositoryConfig)
+}
+
+// RoundTrip serves the canned response for the request URL. Requests for URLs without a canned response get a 404
+// response, so no request ever reaches the network.
+func (fixtures *fixturesType) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.URL.Scheme != "http" && request.URL.Scheme != "https" {
+		// Match the error of the standard transport.
+		return nil, fmt.Errorf("unsupported protocol scheme %q", request.URL.Scheme)
+	}
+
+	httpFixture, ok := fixtures.httpFixtures[request.URL.String()]
+	if !ok {
+		httpFixture = httpFixtureType{Status: http.StatusNotFound}
+	}
+	header := make(http.Header)
+	if httpFixture.Location != "" {
+		header.Set("Location", httpFixture.Location)
+	}
+
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", httpFixture.Status, http.StatusText(httpFixture.Status)),
+		StatusCode: httpFixture.Status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewBufferString(httpFixture.Body)),
+		Request:    request,
+	}, nil
+}
+
+// fixturesGitClient is the GitClient that uses the local bare repositories in place of the remote repositories.
+type fixturesGitClient struct {
+	GitClient              // Client used for the Git operations.
+	repositoriesURL string // file:// URL of the folder containing the local bare repositories.
+}
+
+// RepositoryExists checks the local bare repository for the URL.
+func (gitClient *fixturesGitClient) RepositoryExists(repositoryURL string) (bool, error) {
+	return gitClient.GitClient.RepositoryExists(gitClient.localURL(repositoryURL))
+}
+
+// Clone clones the local bare repository for the URL.
+func (gitClient *fixturesGitClient) Clone(repositoryURL string, clonePath *paths.Path) error {
+	return gitClient.GitClient.Clone(gitClient.localURL(repositoryURL), clonePath)
+}
+
+// localURL returns the URL of the local bare repository for the remote repository URL.
+func (gitClient *fixturesGitClient) localURL(repositoryURL string) string {
+	return gitClient.repositoriesURL + strings.TrimPrefix(strings.TrimPrefix(repositoryURL, "https://"), "http://")
+}
+
+// useFixtures switches all HTTP requests and Git remote operations to the fixtures.
+func useFixtures(fixtures *fixturesType) {
+	installGoGitFileProtocol()
+	httpClient.Transport = fixtures
+	gitClient = &fixturesGitClient{GitClient: gitClient, repositoriesURL: fixtures.repositoriesURL}
+}
@@ -0,0 +1,241 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"io"
+	"net/http"
+	"os/exec"
+	"testing"
+
+	"github.com/arduino/go-paths-helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createFixtures creates a fixtures folder containing a bare repository for `https://github.com/foo/bar` with a tagged
+// library release.
+func createFixtures(t *testing.T) *paths.Path {
+	fixturesPath, err := paths.MkTempDir("", "")
+	require.Nil(t, err)
+	t.Cleanup(func() { fixturesPath.RemoveAll() })
+
+	require.Nil(t, fixturesPath.Join("http.yml").WriteFile([]byte(`
+- url: https://github.com/foo/bar
+- url: https://github.com/foo/private
+  status: 404
+- url: https://github.com/foo/renamed
+  location: https://github.com/foo/bar
+- url: https://github.com/foo/moved
+  status: 302
+  location: https://github.com/foo/bar
+- url: https://api.github.com/repos/foo/bar/collaborators/baz
+  status: 204
+`)))
+
+	libraryPath := fixturesPath.Join("library")
+	require.Nil(t, libraryPath.MkdirAll())
+	require.Nil(t, libraryPath.Join("library.properties").WriteFile([]byte("name=Bar\nversion=1.2.3\n")))
+	bareRepositoryPath := fixturesPath.Join("repositories", "github.com", "foo", "bar.git")
+	for _, arguments := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "--quiet", "--message", "Initial commit"},
+		{"tag", "1.2.3"},
+		{"clone", "--quiet", "--bare", ".", bareRepositoryPath.String()},
+	} {
+		command := exec.Command("git", arguments...)
+		command.Dir = libraryPath.String()
+		output, err := command.CombinedOutput()
+		require.Nil(t, err, string(output))
+	}
+
+	return fixturesPath
+}
+
+// withFixtures uses the fixtures for the duration of the test.
+func withFixtures(t *testing.T, fixturesPath *paths.Path) {
+	fixtures, err := loadFixtures(fixturesPath)
+	require.Nil(t, err)
+	t.Cleanup(fixtures.remove)
+	originalTransport := httpClient.Transport
+	originalGitClient := gitClient
+	t.Cleanup(func() {
+		httpClient.Transport = originalTransport
+		gitClient = originalGitClient
+	})
+	useFixtures(fixtures)
+}
+
+func Test_loadFixtures(t *testing.T) {
+	_, err := loadFixtures(paths.New("/nonexistent"))
+	assert.NotNil(t, err, "Nonexistent folder")
+
+	fixturesPath, err := paths.MkTempDir("", "")
+	require.Nil(t, err)
+	defer fixturesPath.RemoveAll()
+	fixtures, err := loadFixtures(fixturesPath)
+	require.Nil(t, err, "Fixtures files are optional")
+	assert.Empty(t, fixtures.httpFixtures)
+	fixtures.remove()
+	assert.False(t, fixtures.temporaryPath.Exist(), "Temporary folder is removed")
+
+	require.Nil(t, fixturesPath.Join("http.yml").WriteFile([]byte("foo: bar")))
+	_, err = loadFixtures(fixturesPath)
+	assert.NotNil(t, err, "Invalid HTTP fixtures file")
+	require.Nil(t, fixturesPath.Join("http.yml").Remove())
+
+	testTables := []struct {
+		testName              string
+		rawRepositoryFixtures string
+	}{
+		{"Invalid format", "foo: bar"},
+		{"No host", "- url: foo/bar"},
+		{"Duplicate", "- url: https://github.com/foo/bar\n- url: https://github.com/foo/bar.git"},
+		{"File outside repository", "- url: https://github.com/foo/bar\n  commits:\n    - files:\n        ../foo: ''"},
+	}
+
+	for _, testTable := range testTables {
+		require.Nil(t, fixturesPath.Join("repositories.yml").WriteFile([]byte(testTable.rawRepositoryFixtures)), testTable.testName)
+		_, err = loadFixtures(fixturesPath)
+		assert.NotNil(t, err, testTable.testName)
+	}
+}
+
+func Test_createFixtureRepository(t *testing.T) {
+	fixturesPath, err := paths.MkTempDir("", "")
+	require.Nil(t, err)
+	defer fixturesPath.RemoveAll()
+	require.Nil(t, fixturesPath.Join("repositories.yml").WriteFile([]byte(`
+- url: https://github.com/foo/bar
+  commits:
+    - tag: 1.0.0
+      files:
+        library.properties: "name=Bar\nversion=1.0.0\n"
+        src/Bar.h: ""
+    - files:
+        library.properties: "name=Bar\nversion=1.0.1\n"
+    - tag: v2.0.0
+      files:
+        library.properties: "name=Bar\nversion=2.0.0\n"
+- url: https://github.com/foo/empty.git
+`)))
+
+	originalGitClient := gitClient
+	defer func() { gitClient = originalGitClient }()
+	for gitClientName, gitClientFactory := range gitClientFactories {
+		t.Run(gitClientName, func(t *testing.T) {
+			gitClient = gitClientFactory()
+			withFixtures(t, fixturesPath)
+
+			isRepository, err := gitClient.RepositoryExists("https://github.com/foo/empty.git")
+			require.Nil(t, err)
+			assert.True(t, isRepository, "Repository without commits")
+
+			clonePath, err := paths.MkTempDir("", "")
+			require.Nil(t, err)
+			defer clonePath.RemoveAll()
+			require.Nil(t, gitClient.Clone("https://github.com/foo/bar.git", clonePath))
+			require.Nil(t, gitClient.FetchTags(clonePath))
+			tags, err := gitClient.Tags(clonePath)
+			require.Nil(t, err)
+			assert.ElementsMatch(t, []string{"1.0.0", "v2.0.0"}, tags)
+			latestTag, err := gitClient.LatestTag(clonePath)
+			require.Nil(t, err)
+			assert.Equal(t, "v2.0.0", latestTag)
+
+			require.Nil(t, gitClient.Checkout(clonePath, "1.0.0"))
+			libraryProperties, err := clonePath.Join("library.properties").ReadFile()
+			require.Nil(t, err)
+			assert.Equal(t, "name=Bar\nversion=1.0.0\n", string(libraryProperties))
+			assert.True(t, clonePath.Join("src", "Bar.h").Exist())
+
+			require.Nil(t, gitClient.Checkout(clonePath, "v2.0.0"))
+			assert.False(t, clonePath.Join("src", "Bar.h").Exist(), "Files not in the commit are removed")
+		})
+	}
+}
+
+func Test_fixturesRoundTrip(t *testing.T) {
+	withFixtures(t, createFixtures(t))
+
+	response, err := httpClient.Get("https://github.com/foo/bar")
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "Default status")
+
+	response, err = httpClient.Get("https://github.com/foo/private")
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+	response, err = httpClient.Get("https://github.com/foo/renamed")
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode, "Redirect is followed")
+	assert.Equal(t, "https://github.com/foo/bar", response.Request.URL.String())
+
+	response, err = (&http.Client{Transport: httpClient.Transport, CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}).Get("https://github.com/foo/moved")
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusFound, response.StatusCode)
+	assert.Equal(t, "https://github.com/foo/bar", response.Header.Get("Location"))
+
+	response, err = httpClient.Get("https://example.com/unknown")
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode, "No fixture")
+	body, err := io.ReadAll(response.Body)
+	require.Nil(t, err)
+	assert.Empty(t, body)
+
+	_, err = httpClient.Get("foo")
+	assert.EqualError(t, err, `Get "foo": unsupported protocol scheme ""`)
+
+	isCollaborator, err := newGitHubAPIClient("https://api.github.com", "").IsCollaborator("foo", "bar", "baz")
+	require.Nil(t, err)
+	assert.True(t, isCollaborator, "Host API requests use fixtures")
+}
+
+func Test_populateSubmissionFixtures(t *testing.T) {
+	fixturesPath := createFixtures(t)
+	typesConfiguration := []libraryTypeDataType{{Name: "Contributed", Default: true}}
+
+	originalGitClient := gitClient
+	defer func() { gitClient = originalGitClient }()
+	for gitClientName, gitClientFactory := range gitClientFactories {
+		t.Run(gitClientName, func(t *testing.T) {
+			gitClient = gitClientFactory()
+			withFixtures(t, fixturesPath)
+
+			submission, indexEntry, allowed, err := populateSubmission(listChangeType{URL: "https://github.com/foo/bar", File: "repositories.txt", Line: 1}, nil, typesConfiguration, nil, Default, nil, submissionOptionsType{})
+			require.Nil(t, err)
+			assert.True(t, allowed)
+			assert.Equal(t, "", submission.Error)
+			assert.Equal(t, "https://github.com/foo/bar.git", submission.NormalizedURL)
+			assert.Equal(t, "bar", submission.RepositoryName)
+			assert.Equal(t, "Bar", submission.Name)
+			assert.Equal(t, "1.2.3", submission.Tag)
+			assert.Equal(t, &tagSelectionType{Rule: HighestStableVersionRule, Explanation: "Tag `1.2.3` has the highest stable release version of the tags."}, submission.TagSelection)
+			assert.Equal(t, "https://github.com/foo/bar.git|Contributed|Bar", indexEntry)
+
+			submission, _, _, err = populateSubmission(listChangeType{URL: "https://github.com/foo/private"}, nil, typesConfiguration, nil, Default, nil, submissionOptionsType{})
+			require.Nil(t, err)
+			assert.Equal(t, "Unable to load submission URL. Is the repository public?", submission.Error)
+		})
+	}
+}
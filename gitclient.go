@@ -0,0 +1,274 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/arduino/go-paths-helper"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// GitClient is the interface for the Git operations on library repositories.
+type GitClient interface {
+	// RepositoryExists returns whether the URL is a Git clone URL.
+	RepositoryExists(repositoryURL string) (bool, error)
+	// Clone makes a shallow clone of the repository's default branch in the empty folder.
+	Clone(repositoryURL string, clonePath *paths.Path) error
+	// FetchTags fetches all tags of the repository cloned in the folder.
+	FetchTags(clonePath *paths.Path) error
+	// Tags returns the names of the tags of the repository cloned in the folder.
+	Tags(clonePath *paths.Path) ([]string, error)
+	// LatestTag returns the name of the tag of the most recent tagged commit, or an empty string if there are no tags.
+	LatestTag(clonePath *paths.Path) (string, error)
+	// Checkout checks out the tag in the clone.
+	Checkout(clonePath *paths.Path, tag string) error
+}
+
+// gitClientFactories maps the names used in the --gitclient flag to the GitClient constructors.
+var gitClientFactories = map[string]func() GitClient{
+	"exec":   newExecGitClient,
+	"go-git": newGoGitClient,
+}
+
+// gitClient is the client used for all Git operations.
+var gitClient = newExecGitClient()
+
+// execGitClient is the GitClient that runs the git executable.
+type execGitClient struct{}
+
+// newExecGitClient returns the GitClient that runs the git executable.
+func newExecGitClient() GitClient {
+	return &execGitClient{}
+}
+
+func (gitClient *execGitClient) RepositoryExists(repositoryURL string) (bool, error) {
+	err := gitCommand(nil, "ls-remote", repositoryURL).Run()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (gitClient *execGitClient) Clone(repositoryURL string, clonePath *paths.Path) error {
+	output, err := gitCommand(clonePath, "clone", "--depth", "1", repositoryURL, ".").CombinedOutput()
+	if err != nil {
+		return errors.New(commandErrorMessage(err, output))
+	}
+
+	return nil
+}
+
+func (gitClient *execGitClient) FetchTags(clonePath *paths.Path) error {
+	output, err := gitCommand(clonePath, "fetch", "--tags").CombinedOutput()
+	if err != nil {
+		return errors.New(commandErrorMessage(err, output))
+	}
+
+	return nil
+}
+
+func (gitClient *execGitClient) Tags(clonePath *paths.Path) ([]string, error) {
+	tagList, err := gitCommand(clonePath, "tag", "--list").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Fields(string(tagList)), nil
+}
+
+func (gitClient *execGitClient) LatestTag(clonePath *paths.Path) (string, error) {
+	tagList, err := gitCommand(clonePath, "rev-list", "--tags", "--max-count=1").Output()
+	if err != nil {
+		return "", err
+	}
+	if string(tagList) == "" {
+		return "", nil
+	}
+	latestTag, err := gitCommand(clonePath, "describe", "--tags", strings.TrimSpace(string(tagList))).Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(latestTag)), nil
+}
+
+func (gitClient *execGitClient) Checkout(clonePath *paths.Path, tag string) error {
+	output, err := gitCommand(clonePath, "checkout", tag).CombinedOutput()
+	if err != nil {
+		return errors.New(commandErrorMessage(err, output))
+	}
+
+	return nil
+}
+
+// goGitClient is the GitClient that does the Git operations in process, so no git executable is needed.
+type goGitClient struct{}
+
+// newGoGitClient returns the GitClient that does the Git operations in process.
+func newGoGitClient() GitClient {
+	return &goGitClient{}
+}
+
+// installGoGitFileProtocol makes go-git serve file:// URLs in process. By default, go-git runs git-upload-pack for them.
+// Library repositories are never at file:// URLs, so this is only needed for the local repositories of the fixtures.
+func installGoGitFileProtocol() {
+	client.InstallProtocol("file", server.DefaultServer)
+}
+
+// RepositoryExists treats a missing repository, or one that requires authentication, as the URL not being a Git clone
+// URL. Other failures to list the remote references (e.g., network errors) are returned.
+func (gitClient *goGitClient) RepositoryExists(repositoryURL string) (bool, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{repositoryURL}})
+	_, err := remote.List(&git.ListOptions{})
+	switch {
+	case err == nil, errors.Is(err, transport.ErrEmptyRemoteRepository):
+		return true, nil
+	case errors.Is(err, transport.ErrRepositoryNotFound), errors.Is(err, transport.ErrAuthenticationRequired):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// Clone makes a full clone of file:// URLs because the in-process server doesn't support shallow clones.
+func (gitClient *goGitClient) Clone(repositoryURL string, clonePath *paths.Path) error {
+	cloneOptions := git.CloneOptions{URL: repositoryURL, Depth: 1}
+	if strings.HasPrefix(repositoryURL, "file://") {
+		cloneOptions.Depth = 0
+	}
+	_, err := git.PlainClone(clonePath.String(), false, &cloneOptions)
+	return err
+}
+
+func (gitClient *goGitClient) FetchTags(clonePath *paths.Path) error {
+	repository, err := git.PlainOpen(clonePath.String())
+	if err != nil {
+		return err
+	}
+	err = repository.Fetch(&git.FetchOptions{
+		RefSpecs: []config.RefSpec{"+refs/tags/*:refs/tags/*"},
+		Tags:     git.AllTags,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
+
+	return nil
+}
+
+func (gitClient *goGitClient) Tags(clonePath *paths.Path) ([]string, error) {
+	repository, err := git.PlainOpen(clonePath.String())
+	if err != nil {
+		return nil, err
+	}
+	tagReferences, err := repository.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	err = tagReferences.ForEach(func(tagReference *plumbing.Reference) error {
+		tags = append(tags, tagReference.Name().Short())
+		return nil
+	})
+
+	return tags, err
+}
+
+// LatestTag uses the committer time of the tagged commits, as is done by `git rev-list`.
+func (gitClient *goGitClient) LatestTag(clonePath *paths.Path) (string, error) {
+	repository, err := git.PlainOpen(clonePath.String())
+	if err != nil {
+		return "", err
+	}
+	tagReferences, err := repository.Tags()
+	if err != nil {
+		return "", err
+	}
+
+	var latestTag string
+	var latestTagTime time.Time
+	err = tagReferences.ForEach(func(tagReference *plumbing.Reference) error {
+		commit, err := tagCommit(repository, tagReference)
+		if err != nil {
+			return fmt.Errorf("Unable to resolve tag %s: %s", tagReference.Name().Short(), err)
+		}
+		if latestTag == "" || commit.Committer.When.After(latestTagTime) {
+			latestTag = tagReference.Name().Short()
+			latestTagTime = commit.Committer.When
+		}
+		return nil
+	})
+
+	return latestTag, err
+}
+
+func (gitClient *goGitClient) Checkout(clonePath *paths.Path, tag string) error {
+	repository, err := git.PlainOpen(clonePath.String())
+	if err != nil {
+		return err
+	}
+	tagReference, err := repository.Tag(tag)
+	if err != nil {
+		return err
+	}
+	commit, err := tagCommit(repository, tagReference)
+	if err != nil {
+		return err
+	}
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return err
+	}
+
+	return worktree.Checkout(&git.CheckoutOptions{Hash: commit.Hash})
+}
+
+// tagCommit returns the commit of the lightweight or annotated tag.
+func tagCommit(repository *git.Repository, tagReference *plumbing.Reference) (*object.Commit, error) {
+	tag, err := repository.TagObject(tagReference.Hash())
+	switch {
+	case err == nil:
+		return tag.Commit()
+	case errors.Is(err, plumbing.ErrObjectNotFound):
+		return repository.CommitObject(tagReference.Hash())
+	default:
+		return nil, err
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/arduino/go-paths-helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestRepository creates a bare repository with an annotated tag `1.0.0` and a later lightweight tag `1.1.0`, and
+// returns its file:// URL.
+func createTestRepository(t *testing.T) string {
+	folderPath, err := paths.MkTempDir("", "")
+	require.Nil(t, err)
+	t.Cleanup(func() { folderPath.RemoveAll() })
+
+	workingPath := folderPath.Join("working")
+	require.Nil(t, workingPath.MkdirAll())
+	bareRepositoryPath := folderPath.Join("bare.git")
+	runGit := func(date string, arguments ...string) {
+		command := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, arguments...)...)
+		command.Dir = workingPath.String()
+		command.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		output, err := command.CombinedOutput()
+		require.Nil(t, err, string(output))
+	}
+
+	runGit("2020-01-01T00:00:00Z", "init", "--quiet")
+	require.Nil(t, workingPath.Join("library.properties").WriteFile([]byte("name=Foo\nversion=1.0.0\n")))
+	runGit("2020-01-01T00:00:00Z", "add", ".")
+	runGit("2020-01-01T00:00:00Z", "commit", "--quiet", "--message", "Release 1.0.0")
+	runGit("2020-01-01T00:00:00Z", "tag", "--annotate", "--message", "Release 1.0.0", "1.0.0")
+	require.Nil(t, workingPath.Join("library.properties").WriteFile([]byte("name=Foo\nversion=1.1.0\n")))
+	runGit("2021-01-01T00:00:00Z", "commit", "--quiet", "--all", "--message", "Release 1.1.0")
+	runGit("2021-01-01T00:00:00Z", "tag", "1.1.0")
+	runGit("2021-01-01T00:00:00Z", "clone", "--quiet", "--bare", ".", bareRepositoryPath.String())
+
+	return "file://" + bareRepositoryPath.String()
+}
+
+// serveTestRepository serves the repository at the file:// URL over the smart HTTP protocol, which supports shallow
+// clones, and returns its http:// URL.
+func serveTestRepository(t *testing.T, repositoryURL string) string {
+	repositoryPath := paths.New(strings.TrimPrefix(repositoryURL, "file://"))
+	gitPath, err := exec.LookPath("git")
+	require.Nil(t, err)
+	server := httptest.NewServer(&cgi.Handler{
+		Path:   gitPath,
+		Args:   []string{"http-backend"},
+		Env:    []string{"GIT_PROJECT_ROOT=" + repositoryPath.Parent().String(), "GIT_HTTP_EXPORT_ALL=1"},
+		Stderr: io.Discard, // Errors for the nonexistent repository are expected.
+	})
+	t.Cleanup(server.Close)
+
+	return server.URL + "/" + repositoryPath.Base()
+}
+
+func TestGitClient(t *testing.T) {
+	installGoGitFileProtocol()
+	fileURL := createTestRepository(t)
+	hTTPURL := serveTestRepository(t, fileURL)
+
+	for gitClientName, gitClientFactory := range gitClientFactories {
+		testClient := gitClientFactory()
+
+		for _, repositoryURL := range []string{fileURL, hTTPURL} {
+			testName := gitClientName + " " + repositoryURL
+
+			exists, err := testClient.RepositoryExists(repositoryURL)
+			require.Nil(t, err, testName)
+			assert.True(t, exists, testName)
+			exists, err = testClient.RepositoryExists(repositoryURL + "/nonexistent")
+			require.Nil(t, err, testName)
+			assert.False(t, exists, testName)
+
+			clonePath, err := paths.MkTempDir("", "")
+			require.Nil(t, err, testName)
+			defer clonePath.RemoveAll()
+			require.Nil(t, testClient.Clone(repositoryURL, clonePath), testName)
+			if repositoryURL == hTTPURL {
+				assert.True(t, clonePath.Join(".git", "shallow").Exist(), testName+": Clone is shallow")
+			}
+			require.Nil(t, testClient.FetchTags(clonePath), testName)
+
+			tags, err := testClient.Tags(clonePath)
+			require.Nil(t, err, testName)
+			assert.ElementsMatch(t, []string{"1.0.0", "1.1.0"}, tags, testName)
+
+			latestTag, err := testClient.LatestTag(clonePath)
+			require.Nil(t, err, testName)
+			assert.Equal(t, "1.1.0", latestTag, testName)
+
+			require.Nil(t, testClient.Checkout(clonePath, "1.0.0"), testName+": Tag outside of the shallow history")
+			libraryProperties, err := clonePath.Join("library.properties").ReadFile()
+			require.Nil(t, err, testName)
+			assert.Equal(t, "name=Foo\nversion=1.0.0\n", string(libraryProperties), testName)
+
+			assert.NotNil(t, testClient.Checkout(clonePath, "nonexistent"), testName)
+		}
+	}
+}
+
+func TestGoGitClient_RepositoryExists(t *testing.T) {
+	testClient := newGoGitClient()
+	_, err := testClient.RepositoryExists("https://127.0.0.1:1/foo/bar.git")
+	assert.NotNil(t, err, "Network errors are returned")
+}
+
+// fakeGitClient is a GitClient for use in tests that replaces the contents of the clone folder with the files of the tag
+// on checkout.
+type fakeGitClient struct {
+	tags map[string]map[string]string // Contents of the files of each tag, indexed by tag name and file name.
+}
+
+func (gitClient *fakeGitClient) RepositoryExists(repositoryURL string) (bool, error) {
+	return true, nil
+}
+
+func (gitClient *fakeGitClient) Clone(repositoryURL string, clonePath *paths.Path) error {
+	return nil
+}
+
+func (gitClient *fakeGitClient) FetchTags(clonePath *paths.Path) error {
+	return nil
+}
+
+func (gitClient *fakeGitClient) Tags(clonePath *paths.Path) ([]string, error) {
+	var tags []string
+	for tag := range gitClient.tags {
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+func (gitClient *fakeGitClient) LatestTag(clonePath *paths.Path) (string, error) {
+	latestTag := ""
+	for tag := range gitClient.tags {
+		if tag > latestTag {
+			latestTag = tag
+		}
+	}
+
+	return latestTag, nil
+}
+
+func (gitClient *fakeGitClient) Checkout(clonePath *paths.Path, tag string) error {
+	if _, ok := gitClient.tags[tag]; !ok {
+		return fmt.Errorf("Tag %s not found", tag)
+	}
+	if err := clonePath.RemoveAll(); err != nil {
+		return err
+	}
+	if err := clonePath.MkdirAll(); err != nil {
+		return err
+	}
+	for fileName, content := range gitClient.tags[tag] {
+		if err := clonePath.Join(fileName).WriteFile([]byte(content)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Test_populateSubmissionGitClient(t *testing.T) {
+	testTables := []struct {
+		testName      string
+		tags          map[string]map[string]string
+		expectedTag   string
+		expectedError string
+	}{
+		{"No tags", nil, "", "The repository has no tags. You need to create a [release](https://docs.github.com/en/github/administering-a-repository/managing-releases-in-a-repository) or [tag](https://git-scm.com/docs/git-tag) that matches the `version` value in the library's library.properties file."},
+		{"No library.properties", map[string]map[string]string{"1.0.0": {"README.md": ""}}, "1.0.0", "Library is missing a library.properties metadata file.%0A%0ASee: https://arduino.github.io/arduino-cli/latest/library-specification/#library-metadata"},
+		{"Valid", map[string]map[string]string{"1.0.0": {"library.properties": "name=Foo\nversion=1.0.0\n"}}, "1.0.0", ""},
+	}
+
+	for _, testTable := range testTables {
+		withTestRepository(t, testTable.tags)
+		submission, _, err := populateTestSubmission(listChangeType{URL: "https://github.com/foo/bar"}, submissionOptionsType{})
+		require.Nil(t, err, testTable.testName)
+		assert.Equal(t, testTable.expectedTag, submission.Tag, testTable.testName)
+		assert.Equal(t, testTable.expectedError, submission.Error, testTable.testName)
+	}
+}
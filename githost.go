@@ -0,0 +1,243 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GitHostProvider is the interface for the host-specific handling of library repository URLs.
+type GitHostProvider interface {
+	// Host returns the hostname of the Git host (e.g., `github.com`).
+	Host() string
+	// NormalizeURL converts the URL into the standardized format used in the index.
+	NormalizeURL(rawURL *url.URL) url.URL
+	// OwnerAndRepository returns the path of the account that owns the repository and the repository name.
+	OwnerAndRepository(normalizedURL url.URL) (string, string, error)
+	// RepositoryExists returns whether the URL is a Git clone URL.
+	RepositoryExists(normalizedURL url.URL) (bool, error)
+}
+
+// gitHostConfigurationType is the type of the Git host configuration data.
+type gitHostConfigurationType struct {
+	Provider string   `yaml:"provider"` // Name of the GitHostProvider implementation used for the hosts.
+	Hosts    []string `yaml:"hosts"`    // Hostnames (e.g., `github.com`).
+}
+
+// defaultGitHostsConfiguration is the configuration of the Git hosts that are supported for library repositories when
+// no Git hosts configuration file is provided.
+var defaultGitHostsConfiguration = []gitHostConfigurationType{
+	{Provider: "antares", Hosts: []string{"git.antares.id"}},
+	{Provider: "bitbucket", Hosts: []string{"bitbucket.org"}},
+	{Provider: "github", Hosts: []string{"github.com"}},
+	{Provider: "gitlab", Hosts: []string{"gitlab.com"}},
+}
+
+// gitHostProviderFactories maps the provider names used in the configuration to the GitHostProvider constructors.
+var gitHostProviderFactories = map[string]func(host string) GitHostProvider{
+	"antares":          newAntaresGitHostProvider,
+	"bitbucket":        newBitbucketGitHostProvider,
+	"generic":          newGenericGitHostProvider,
+	"github":           newGitHubGitHostProvider,
+	"gitlab":           newGitLabGitHostProvider,
+	"owner-repository": newOwnerRepositoryGitHostProvider,
+}
+
+// gitHostProviders contains the providers for the supported Git hosts, indexed by hostname. gitHostProvidersError is
+// the error from registering the providers of the built-in configuration, which is reported by main.
+var gitHostProviders, gitHostProvidersError = registerGitHostProviders(defaultGitHostsConfiguration)
+
+// parseGitHostsConfiguration parses the Git hosts configuration file data and returns the providers for the hosts,
+// indexed by hostname.
+func parseGitHostsConfiguration(rawGitHostsConfiguration []byte) (map[string]GitHostProvider, error) {
+	var gitHostsConfiguration []gitHostConfigurationType
+	decoder := yaml.NewDecoder(bytes.NewReader(rawGitHostsConfiguration))
+	decoder.KnownFields(true) // Catch misspelled keys, which would otherwise silently drop hosts.
+	if err := decoder.Decode(&gitHostsConfiguration); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return registerGitHostProviders(gitHostsConfiguration)
+}
+
+// registerGitHostProviders returns the providers for the Git hosts in the configuration, indexed by hostname.
+func registerGitHostProviders(configuration []gitHostConfigurationType) (map[string]GitHostProvider, error) {
+	providers := make(map[string]GitHostProvider)
+	for _, providerConfiguration := range configuration {
+		factory, ok := gitHostProviderFactories[providerConfiguration.Provider]
+		if !ok {
+			return nil, fmt.Errorf("Unknown Git host provider %s", providerConfiguration.Provider)
+		}
+		if len(providerConfiguration.Hosts) == 0 {
+			return nil, fmt.Errorf("Git host configuration for provider %s has no hosts", providerConfiguration.Provider)
+		}
+		for _, host := range providerConfiguration.Hosts {
+			host = strings.ToLower(host)
+			if host == "" {
+				return nil, fmt.Errorf("Git host configuration for provider %s has an empty host", providerConfiguration.Provider)
+			}
+			if _, registered := providers[host]; registered {
+				return nil, fmt.Errorf("Git host %s is configured multiple times", host)
+			}
+			providers[host] = factory(host)
+		}
+	}
+
+	return providers, nil
+}
+
+// gitHostProviderForURL returns the provider for the URL's host and whether the host is supported. The generic provider
+// is returned for unsupported hosts.
+func gitHostProviderForURL(rawURL *url.URL) (GitHostProvider, bool) {
+	host := strings.ToLower(rawURL.Host)
+	provider, supported := gitHostProviders[host]
+	if !supported {
+		return newGenericGitHostProvider(host), false
+	}
+
+	return provider, true
+}
+
+// genericGitHostProvider is the GitHostProvider for hosts that don't have any special handling requirements.
+type genericGitHostProvider struct {
+	host string
+}
+
+func newGenericGitHostProvider(host string) GitHostProvider {
+	return &genericGitHostProvider{host: host}
+}
+
+func (provider *genericGitHostProvider) Host() string {
+	return provider.host
+}
+
+func (provider *genericGitHostProvider) NormalizeURL(rawURL *url.URL) url.URL {
+	return normalizeURL(rawURL)
+}
+
+// OwnerAndRepository treats the final path element as the repository name and all preceding elements as the owner.
+func (provider *genericGitHostProvider) OwnerAndRepository(normalizedURL url.URL) (string, string, error) {
+	pathElements := uRLPathElements(normalizedURL)
+	if len(pathElements) < 2 {
+		return "", "", fmt.Errorf("URL %s does not have owner and repository path elements", normalizedURL.String())
+	}
+
+	return strings.Join(pathElements[:len(pathElements)-1], "/"), pathElements[len(pathElements)-1], nil
+}
+
+func (provider *genericGitHostProvider) RepositoryExists(normalizedURL url.URL) (bool, error) {
+	return gitClient.RepositoryExists(normalizedURL.String())
+}
+
+// ownerRepositoryGitHostProvider is the GitHostProvider for hosts where repositories are always located at
+// `<owner>/<repository>`.
+type ownerRepositoryGitHostProvider struct {
+	genericGitHostProvider
+}
+
+func (provider *ownerRepositoryGitHostProvider) OwnerAndRepository(normalizedURL url.URL) (string, string, error) {
+	pathElements := uRLPathElements(normalizedURL)
+	if len(pathElements) != 2 {
+		return "", "", fmt.Errorf("URL %s is not in the format %s/<owner>/<repository>", normalizedURL.String(), provider.host)
+	}
+
+	return pathElements[0], pathElements[1], nil
+}
+
+// newOwnerRepositoryGitHostProvider returns the GitHostProvider for a host where repositories are always located at
+// `<owner>/<repository>`.
+func newOwnerRepositoryGitHostProvider(host string) GitHostProvider {
+	return &ownerRepositoryGitHostProvider{genericGitHostProvider{host: host}}
+}
+
+// gitHubGitHostProvider is the GitHostProvider for GitHub.
+type gitHubGitHostProvider struct {
+	ownerRepositoryGitHostProvider
+}
+
+// newGitHubGitHostProvider returns the GitHostProvider for GitHub.
+func newGitHubGitHostProvider(host string) GitHostProvider {
+	return &gitHubGitHostProvider{ownerRepositoryGitHostProvider{genericGitHostProvider{host: host}}}
+}
+
+// bitbucketGitHostProvider is the GitHostProvider for Bitbucket. The owner of Bitbucket repositories is the workspace.
+type bitbucketGitHostProvider struct {
+	ownerRepositoryGitHostProvider
+}
+
+// newBitbucketGitHostProvider returns the GitHostProvider for Bitbucket.
+func newBitbucketGitHostProvider(host string) GitHostProvider {
+	return &bitbucketGitHostProvider{ownerRepositoryGitHostProvider{genericGitHostProvider{host: host}}}
+}
+
+// antaresGitHostProvider is the GitHostProvider for the git.antares.id Gitea instance.
+type antaresGitHostProvider struct {
+	ownerRepositoryGitHostProvider
+}
+
+// newAntaresGitHostProvider returns the GitHostProvider for the git.antares.id Gitea instance.
+func newAntaresGitHostProvider(host string) GitHostProvider {
+	return &antaresGitHostProvider{ownerRepositoryGitHostProvider{genericGitHostProvider{host: host}}}
+}
+
+// gitLabGitHostProvider is the GitHostProvider for GitLab. GitLab repositories may be nested under any number of
+// subgroups, so the owner is the full namespace path.
+type gitLabGitHostProvider struct {
+	genericGitHostProvider
+}
+
+// newGitLabGitHostProvider returns the GitHostProvider for GitLab.
+func newGitLabGitHostProvider(host string) GitHostProvider {
+	return &gitLabGitHostProvider{genericGitHostProvider{host: host}}
+}
+
+// NormalizeURL removes the `/-/` separated suffix GitLab uses for pages within a repository (e.g.,
+// `https://gitlab.com/foo/bar/-/tree/main`) before doing the standard normalization.
+func (provider *gitLabGitHostProvider) NormalizeURL(rawURL *url.URL) url.URL {
+	trimmedURL := *rawURL
+	if separatorIndex := strings.Index(trimmedURL.Path, "/-/"); separatorIndex >= 0 {
+		trimmedURL.Path = trimmedURL.Path[:separatorIndex]
+	} else {
+		trimmedURL.Path = strings.TrimSuffix(trimmedURL.Path, "/-")
+	}
+
+	return normalizeURL(&trimmedURL)
+}
+
+// uRLPathElements returns the non-empty elements of the URL path, without the `.git` extension.
+func uRLPathElements(normalizedURL url.URL) []string {
+	var pathElements []string
+	for _, pathElement := range strings.Split(strings.TrimSuffix(normalizedURL.Path, ".git"), "/") {
+		if pathElement != "" {
+			pathElements = append(pathElements, pathElement)
+		}
+	}
+
+	return pathElements
+}
@@ -0,0 +1,168 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_registerGitHostProviders(t *testing.T) {
+	require.Nil(t, gitHostProvidersError, "Built-in configuration is valid")
+	providers, err := registerGitHostProviders(defaultGitHostsConfiguration)
+	require.Nil(t, err)
+	for host, expectedProvider := range map[string]GitHostProvider{
+		"bitbucket.org":  &bitbucketGitHostProvider{},
+		"git.antares.id": &antaresGitHostProvider{},
+		"github.com":     &gitHubGitHostProvider{},
+		"gitlab.com":     &gitLabGitHostProvider{},
+	} {
+		provider, ok := providers[host]
+		require.True(t, ok, host)
+		assert.IsType(t, expectedProvider, provider, host)
+		assert.Equal(t, host, provider.Host(), host)
+	}
+
+	providers, err = registerGitHostProviders([]gitHostConfigurationType{{Provider: "generic", Hosts: []string{"Example.com", "example.org"}}})
+	require.Nil(t, err)
+	assert.Contains(t, providers, "example.com", "Host is case-insensitive")
+	assert.Contains(t, providers, "example.org", "Multiple hosts")
+
+	_, err = registerGitHostProviders([]gitHostConfigurationType{{Provider: "foo", Hosts: []string{"example.com"}}})
+	assert.NotNil(t, err, "Unknown provider")
+
+	_, err = registerGitHostProviders([]gitHostConfigurationType{{Provider: "generic"}})
+	assert.NotNil(t, err, "No hosts")
+
+	_, err = registerGitHostProviders([]gitHostConfigurationType{{Provider: "generic", Hosts: []string{""}}})
+	assert.NotNil(t, err, "Empty host")
+
+	_, err = registerGitHostProviders([]gitHostConfigurationType{{Provider: "generic", Hosts: []string{"example.com"}}, {Provider: "owner-repository", Hosts: []string{"example.com"}}})
+	assert.NotNil(t, err, "Duplicate host")
+}
+
+func Test_parseGitHostsConfiguration(t *testing.T) {
+	providers, err := parseGitHostsConfiguration([]byte(`
+- provider: github
+  hosts:
+    - github.com
+- provider: owner-repository
+  hosts:
+    - gitea.example.com
+`))
+	require.Nil(t, err)
+	assert.IsType(t, &gitHubGitHostProvider{}, providers["github.com"])
+	assert.IsType(t, &ownerRepositoryGitHostProvider{}, providers["gitea.example.com"])
+	assert.Len(t, providers, 2)
+
+	providers, err = parseGitHostsConfiguration([]byte(""))
+	require.Nil(t, err)
+	assert.Empty(t, providers, "Empty file")
+
+	_, err = parseGitHostsConfiguration([]byte("- provider: github\n  host: github.com\n"))
+	assert.NotNil(t, err, "Unknown key")
+
+	_, err = parseGitHostsConfiguration([]byte("- provider: foo\n  hosts: [example.com]\n"))
+	assert.NotNil(t, err, "Invalid configuration")
+}
+
+func Test_gitHostProviderForURL(t *testing.T) {
+	testTables := []struct {
+		testName          string
+		rawURL            string
+		expectedHost      string
+		expectedSupported assert.BoolAssertionFunc
+	}{
+		{"Supported", "https://github.com/foo/bar", "github.com", assert.True},
+		{"Supported, uppercase", "https://GitHub.com/foo/bar", "github.com", assert.True},
+		{"Unsupported", "https://example.com/foo/bar", "example.com", assert.False},
+	}
+
+	for _, testTable := range testTables {
+		rawURL, err := url.Parse(testTable.rawURL)
+		require.Nil(t, err)
+
+		provider, supported := gitHostProviderForURL(rawURL)
+		assert.Equal(t, testTable.expectedHost, provider.Host(), testTable.testName)
+		testTable.expectedSupported(t, supported, testTable.testName)
+	}
+}
+
+func TestGitHostProvider_NormalizeURL(t *testing.T) {
+	testTables := []struct {
+		testName              string
+		rawURL                string
+		expectedNormalizedURL string
+	}{
+		{"GitHub", "https://github.com/foo/bar/", "https://github.com/foo/bar.git"},
+		{"GitLab", "https://gitlab.com/foo/bar", "https://gitlab.com/foo/bar.git"},
+		{"GitLab subgroup", "https://gitlab.com/foo/baz/bar", "https://gitlab.com/foo/baz/bar.git"},
+		{"GitLab repository page", "https://gitlab.com/foo/baz/bar/-/tree/main", "https://gitlab.com/foo/baz/bar.git"},
+		{"GitLab repository page separator", "https://gitlab.com/foo/bar/-", "https://gitlab.com/foo/bar.git"},
+		{"Bitbucket", "https://bitbucket.org/foo/bar", "https://bitbucket.org/foo/bar.git"},
+		{"Unsupported", "http://example.com/foo/bar", "https://example.com/foo/bar.git"},
+	}
+
+	for _, testTable := range testTables {
+		rawURL, err := url.Parse(testTable.rawURL)
+		require.Nil(t, err)
+		expectedNormalizedURL, err := url.Parse(testTable.expectedNormalizedURL)
+		require.Nil(t, err)
+
+		provider, _ := gitHostProviderForURL(rawURL)
+		assert.Equal(t, *expectedNormalizedURL, provider.NormalizeURL(rawURL), testTable.testName)
+	}
+}
+
+func TestGitHostProvider_OwnerAndRepository(t *testing.T) {
+	testTables := []struct {
+		testName           string
+		normalizedURL      string
+		expectedOwner      string
+		expectedRepository string
+		errorAssertion     assert.ValueAssertionFunc
+	}{
+		{"GitHub", "https://github.com/foo/bar.git", "foo", "bar", assert.Nil},
+		{"GitHub, extra path", "https://github.com/foo/bar/releases.git", "", "", assert.NotNil},
+		{"GitHub, root", "https://github.com/", "", "", assert.NotNil},
+		{"GitLab", "https://gitlab.com/foo/bar.git", "foo", "bar", assert.Nil},
+		{"GitLab subgroups", "https://gitlab.com/foo/baz/qux/bar.git", "foo/baz/qux", "bar", assert.Nil},
+		{"Bitbucket workspace", "https://bitbucket.org/foo/bar.git", "foo", "bar", assert.Nil},
+		{"Antares", "https://git.antares.id/foo/bar.git", "foo", "bar", assert.Nil},
+		{"Generic", "https://example.com/foo/baz/bar.git", "foo/baz", "bar", assert.Nil},
+		{"Generic, no owner", "https://example.com/bar.git", "", "", assert.NotNil},
+	}
+
+	for _, testTable := range testTables {
+		normalizedURL, err := url.Parse(testTable.normalizedURL)
+		require.Nil(t, err)
+
+		provider, _ := gitHostProviderForURL(normalizedURL)
+		owner, repository, err := provider.OwnerAndRepository(*normalizedURL)
+		testTable.errorAssertion(t, err, testTable.testName)
+		assert.Equal(t, testTable.expectedOwner, owner, testTable.testName)
+		assert.Equal(t, testTable.expectedRepository, repository, testTable.testName)
+	}
+}
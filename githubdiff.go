@@ -0,0 +1,101 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/sourcegraph/go-diff/diff"
+)
+
+// Formats of the request diff data.
+const (
+	unifiedDiffFormat    = "unified"     // Unified diff, as produced by `git diff`.
+	gitHubJSONDiffFormat = "github-json" // Response of the GitHub "List pull requests files" API endpoint.
+)
+
+// gitHubPullRequestFileType is the type of the data for each file in the GitHub pull request files API response.
+type gitHubPullRequestFileType struct {
+	Filename         string `json:"filename"`          // Path of the file in the PR head.
+	Status           string `json:"status"`            // Type of change (e.g., `added`, `removed`, `modified`, `renamed`).
+	PreviousFilename string `json:"previous_filename"` // For renamed files, the path of the file in the PR base.
+	Changes          int    `json:"changes"`           // Number of changed lines.
+	Patch            string `json:"patch"`             // Hunks of the file's unified diff. Omitted by GitHub for binary and very large diffs.
+}
+
+// parseGitHubJSONDiff parses the request diff in the format of the GitHub pull request files API response and returns the
+// request type, request error, `arduino-lint --library-manager` setting, list of submissions, list of removals, and
+// internal error.
+func parseGitHubJSONDiff(rawFiles []byte, listName string) (string, string, string, []listChangeType, []listChangeType, error) {
+	var files []gitHubPullRequestFileType
+	err := json.Unmarshal(rawFiles, &files)
+	if err != nil {
+		return "", "", "", nil, nil, newInternalError(DiffParseError, "Unable to parse GitHub pull request files data: %s", err)
+	}
+
+	var diffs []*diff.FileDiff
+	for _, file := range files {
+		if strings.Contains(file.Patch, noNewlineMarker) {
+			return "invalid", noNewlineError, "", nil, nil, nil
+		}
+
+		fileDiff, err := gitHubFileDiff(file)
+		if err != nil {
+			return "", "", "", nil, nil, err
+		}
+		diffs = append(diffs, fileDiff)
+	}
+
+	return classifyDiff(diffs, listName)
+}
+
+// gitHubFileDiff converts the GitHub pull request file data to the equivalent parsed unified diff.
+func gitHubFileDiff(file gitHubPullRequestFileType) (*diff.FileDiff, error) {
+	if file.Patch == "" && file.Changes > 0 {
+		return nil, newInternalError(DiffParseError, "GitHub pull request files data has no patch for %s", file.Filename)
+	}
+
+	// Use the a/ and b/ prefixes of Git diffs, and /dev/null for the missing side of added and removed files.
+	fileDiff := diff.FileDiff{
+		OrigName: "a/" + file.Filename,
+		NewName:  "b/" + file.Filename,
+	}
+	switch file.Status {
+	case "added":
+		fileDiff.OrigName = "/dev/null"
+	case "removed":
+		fileDiff.NewName = "/dev/null"
+	case "renamed":
+		fileDiff.OrigName = "a/" + file.PreviousFilename
+	}
+
+	if file.Patch != "" {
+		hunks, err := diff.ParseHunks([]byte(file.Patch + "\n"))
+		if err != nil {
+			return nil, newInternalError(DiffParseError, "Unable to parse patch for %s: %s", file.Filename, err)
+		}
+		fileDiff.Hunks = hunks
+	}
+
+	return &fileDiff, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseGitHubJSONDiff(t *testing.T) {
+	testTables := []struct {
+		testName                                 string
+		rawFiles                                 string
+		expectedRequestType                      string
+		expectedRequestError                     string
+		expectedArduinoLintLibraryManagerSetting string
+		expectedSubmissions                      []listChangeType
+		expectedRemovals                         []listChangeType
+	}{
+		{
+			testName:            "Multiple files",
+			rawFiles:            `[{"filename": "README.md", "status": "modified", "changes": 1, "patch": "@@ -1,0 +2 @@\n+hello"}, {"filename": "repositories.txt", "status": "modified", "changes": 1, "patch": "@@ -8,0 +9 @@\n+https://github.com/foo/bar"}]`,
+			expectedRequestType: "other",
+		},
+		{
+			testName:            "Not list",
+			rawFiles:            `[{"filename": "README.md", "status": "modified", "changes": 1, "patch": "@@ -1 +1,2 @@\n # Arduino Library Manager list\n+hello"}]`,
+			expectedRequestType: "other",
+		},
+		{
+			testName:            "List deleted",
+			rawFiles:            `[{"filename": "repositories.txt", "status": "removed", "changes": 1, "patch": "@@ -1 +0,0 @@\n-https://github.com/arduino-libraries/Ethernet"}]`,
+			expectedRequestType: "other",
+		},
+		{
+			testName:            "List renamed",
+			rawFiles:            `[{"filename": "foo.txt", "previous_filename": "repositories.txt", "status": "renamed", "changes": 0}]`,
+			expectedRequestType: "other",
+		},
+		{
+			testName:             "No final newline",
+			rawFiles:             `[{"filename": "repositories.txt", "status": "modified", "changes": 2, "patch": "@@ -1 +1 @@\n-https://github.com/foo/bar\n+https://github.com/foo/bar\n\\ No newline at end of file"}]`,
+			expectedRequestType:  "invalid",
+			expectedRequestError: "Pull request removes newline from the end of a file.%0APlease add a blank line to the end of the file.",
+		},
+		{
+			testName:                                 "Submission",
+			rawFiles:                                 `[{"filename": "repositories.txt", "status": "modified", "changes": 2, "patch": "@@ -8,0 +9,2 @@ https://github.com/arduino-libraries/Ethernet\n+https://github.com/foo/bar\n+https://github.com/foo/baz"}]`,
+			expectedRequestType:                      "submission",
+			expectedArduinoLintLibraryManagerSetting: "submit",
+			expectedSubmissions:                      []listChangeType{{URL: "https://github.com/foo/bar", File: "repositories.txt", Line: 9}, {URL: "https://github.com/foo/baz", File: "repositories.txt", Line: 10}},
+		},
+		{
+			testName:            "Removal",
+			rawFiles:            `[{"filename": "repositories.txt", "status": "modified", "changes": 1, "patch": "@@ -7,3 +7,2 @@\n https://github.com/arduino-libraries/Bridge\n-https://github.com/arduino-libraries/Ethernet\n https://github.com/arduino-libraries/Servo"}]`,
+			expectedRequestType: "removal",
+			expectedRemovals:    []listChangeType{{URL: "https://github.com/arduino-libraries/Ethernet", File: "repositories.txt", Line: 8}},
+		},
+		{
+			testName:                                 "Modification",
+			rawFiles:                                 `[{"filename": "repositories.txt", "status": "modified", "changes": 2, "patch": "@@ -8 +8 @@\n-https://github.com/arduino-libraries/Ethernet\n+https://github.com/foo/bar"}]`,
+			expectedRequestType:                      "modification",
+			expectedArduinoLintLibraryManagerSetting: "update",
+			expectedSubmissions:                      []listChangeType{{URL: "https://github.com/foo/bar", ReplacedURL: "https://github.com/arduino-libraries/Ethernet", File: "repositories.txt", Line: 8}},
+			expectedRemovals:                         []listChangeType{{URL: "https://github.com/arduino-libraries/Ethernet", File: "repositories.txt", Line: 8}},
+		},
+	}
+
+	for _, testTable := range testTables {
+		requestType, requestError, arduinoLintLibraryManagerSetting, submissions, removals, err := parseGitHubJSONDiff([]byte(testTable.rawFiles), "repositories.txt")
+		require.Nil(t, err, testTable.testName)
+		assert.Equal(t, testTable.expectedRequestType, requestType, testTable.testName)
+		assert.Equal(t, testTable.expectedRequestError, requestError, testTable.testName)
+		assert.Equal(t, testTable.expectedArduinoLintLibraryManagerSetting, arduinoLintLibraryManagerSetting, testTable.testName)
+		assert.Equal(t, testTable.expectedSubmissions, submissions, testTable.testName)
+		assert.Equal(t, testTable.expectedRemovals, removals, testTable.testName)
+	}
+}
+
+func Test_parseGitHubJSONDiffInternalError(t *testing.T) {
+	testTables := []struct {
+		testName string
+		rawFiles string
+	}{
+		{"Invalid JSON", `{"filename": "repositories.txt"}`},
+		{"Missing patch", `[{"filename": "repositories.txt", "status": "modified", "changes": 4000}]`},
+		{"Invalid patch", `[{"filename": "repositories.txt", "status": "modified", "changes": 1, "patch": "@@ -foo"}]`},
+	}
+
+	for _, testTable := range testTables {
+		_, _, _, _, _, err := parseGitHubJSONDiff([]byte(testTable.rawFiles), "repositories.txt")
+		var parseError *internalError
+		require.ErrorAs(t, err, &parseError, testTable.testName)
+		assert.Equal(t, DiffParseError, parseError.Code, testTable.testName)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// HostAPIClient is the interface for the Git host APIs used to get information about library repositories.
+type HostAPIClient interface {
+	// IsCollaborator returns whether the user has collaborator access to the repository.
+	IsCollaborator(owner string, repository string, username string) (bool, error)
+	// ReleaseTags returns the tag names of the repository's published releases.
+	ReleaseTags(owner string, repository string) ([]string, error)
+}
+
+// hostAPIClients contains the API clients for the Git hosts that provide them, indexed by hostname. Only GitHub is
+// supported, since the submitter is identified by their GitHub username.
+var hostAPIClients = map[string]HostAPIClient{
+	"github.com": newGitHubAPIClient("https://api.github.com", os.Getenv("GITHUB_TOKEN")),
+}
+
+// errCollaboratorAccessDenied is returned by HostAPIClient.IsCollaborator when the API token is not allowed to check
+// the repository's collaborators.
+var errCollaboratorAccessDenied = errors.New("API token is not allowed to check the repository's collaborators")
+
+// gitHubAPIClient is the HostAPIClient for the GitHub REST API.
+type gitHubAPIClient struct {
+	baseURL    string       // Base URL of the API (e.g., `https://api.github.com`).
+	token      string       // Access token used for authentication. Unauthenticated requests are made if empty.
+	httpClient *http.Client // Client used to make the API requests.
+}
+
+func newGitHubAPIClient(baseURL string, token string) HostAPIClient {
+	return &gitHubAPIClient{baseURL: baseURL, token: token, httpClient: httpClient}
+}
+
+// IsCollaborator uses the "check if a user is a repository collaborator" endpoint. GitHub only allows this for tokens
+// with push access to the repository, so errCollaboratorAccessDenied is returned for most library repositories.
+func (client *gitHubAPIClient) IsCollaborator(owner string, repository string, username string) (bool, error) {
+	response, err := client.get(fmt.Sprintf("/repos/%s/%s/collaborators/%s", url.PathEscape(owner), url.PathEscape(repository), url.PathEscape(username)))
+	if err != nil {
+		return false, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusNoContent:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	case http.StatusForbidden:
+		return false, errCollaboratorAccessDenied
+	default:
+		return false, fmt.Errorf("GitHub API responded with status %s", response.Status)
+	}
+}
+
+// ReleaseTags uses the "list releases" endpoint. Draft releases are not published, so they are skipped. Only the most
+// recent 100 releases are returned.
+func (client *gitHubAPIClient) ReleaseTags(owner string, repository string) ([]string, error) {
+	response, err := client.get(fmt.Sprintf("/repos/%s/%s/releases?per_page=100", url.PathEscape(owner), url.PathEscape(repository)))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API responded with status %s", response.Status)
+	}
+
+	var releases []struct {
+		TagName string `json:"tag_name"`
+		Draft   bool   `json:"draft"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&releases)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse GitHub API response: %s", err)
+	}
+
+	var releaseTags []string
+	for _, release := range releases {
+		if !release.Draft {
+			releaseTags = append(releaseTags, release.TagName)
+		}
+	}
+
+	return releaseTags, nil
+}
+
+// get makes a GET request to the API endpoint at the path.
+func (client *gitHubAPIClient) get(path string) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodGet, client.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/vnd.github+json")
+	if client.token != "" {
+		request.Header.Set("Authorization", "Bearer "+client.token)
+	}
+
+	return client.httpClient.Do(request)
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubAPIClient_IsCollaborator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "Bearer foo-token", request.Header.Get("Authorization"))
+		switch request.URL.Path {
+		case "/repos/foo/bar/collaborators/Collaborator":
+			writer.WriteHeader(http.StatusNoContent)
+		case "/repos/foo/bar/collaborators/Forbidden":
+			writer.WriteHeader(http.StatusForbidden)
+		case "/repos/foo/bar/collaborators/Error":
+			writer.WriteHeader(http.StatusInternalServerError)
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newGitHubAPIClient(server.URL, "foo-token")
+
+	isCollaborator, err := client.IsCollaborator("foo", "bar", "Collaborator")
+	require.Nil(t, err)
+	assert.True(t, isCollaborator)
+
+	isCollaborator, err = client.IsCollaborator("foo", "bar", "SomeUser")
+	require.Nil(t, err)
+	assert.False(t, isCollaborator)
+
+	_, err = client.IsCollaborator("foo", "bar", "Forbidden")
+	assert.ErrorIs(t, err, errCollaboratorAccessDenied, "No push access to the repository")
+
+	_, err = client.IsCollaborator("foo", "bar", "Error")
+	assert.NotNil(t, err)
+	assert.NotErrorIs(t, err, errCollaboratorAccessDenied)
+}
+
+func TestGitHubAPIClient_ReleaseTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/repos/foo/bar/releases":
+			assert.Equal(t, "100", request.URL.Query().Get("per_page"))
+			writer.Write([]byte(`[{"tag_name": "1.1.0", "draft": true}, {"tag_name": "1.0.0", "draft": false, "prerelease": false}]`))
+		case "/repos/foo/invalid/releases":
+			writer.Write([]byte(`{`))
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newGitHubAPIClient(server.URL, "")
+
+	releaseTags, err := client.ReleaseTags("foo", "bar")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"1.0.0"}, releaseTags, "Draft releases are skipped")
+
+	_, err = client.ReleaseTags("foo", "invalid")
+	assert.NotNil(t, err)
+
+	_, err = client.ReleaseTags("foo", "nonexistent")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// libraryIndexReleaseType is the type of the data for a library release in the Library Manager index.
+type libraryIndexReleaseType struct {
+	Name         string                       `json:"name"`         // Library name.
+	Version      string                       `json:"version"`      // Release version.
+	Repository   string                       `json:"repository"`   // Library repository URL.
+	Dependencies []libraryIndexDependencyType `json:"dependencies"` // Libraries the release depends on.
+}
+
+// libraryIndexDependencyType is the type of the data for a dependency of a library release in the Library Manager index.
+type libraryIndexDependencyType struct {
+	Name    string `json:"name"`    // Name of the library dependency.
+	Version string `json:"version"` // Constraint on the dependency version. Any version is allowed if empty.
+}
+
+// libraryIndexType is the type of the data of the libraries in the Library Manager index.
+type libraryIndexType struct {
+	Libraries []libraryIndexReleaseType `json:"libraries"` // Library releases.
+
+	repositories map[string]string                    // Normalized repository URL of each library, indexed by the case folded library name.
+	names        map[string]string                    // Name of each library, indexed by the normalized repository URL.
+	releases     map[string][]libraryIndexReleaseType // Releases of each library, indexed by the library name.
+}
+
+// parseLibraryIndex parses the Library Manager index data, which may be either the `library_index.json` file or the
+// index source file, which has lines in the `<repository URL>|<types>|<name>` format used by the index entries.
+func parseLibraryIndex(rawLibraryIndex []byte) (*libraryIndexType, error) {
+	var libraryIndex libraryIndexType
+	if bytes.HasPrefix(bytes.TrimSpace(rawLibraryIndex), []byte("{")) {
+		if err := json.Unmarshal(rawLibraryIndex, &libraryIndex); err != nil {
+			return nil, err
+		}
+	} else {
+		for lineIndex, line := range strings.Split(string(rawLibraryIndex), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			fields := strings.Split(line, "|")
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("Line %d is not in the format <repository URL>|<types>|<name>", lineIndex+1)
+			}
+			libraryIndex.Libraries = append(libraryIndex.Libraries, libraryIndexReleaseType{Name: fields[2], Repository: fields[0]})
+		}
+	}
+
+	libraryIndex.repositories = make(map[string]string)
+	libraryIndex.names = make(map[string]string)
+	libraryIndex.releases = make(map[string][]libraryIndexReleaseType)
+	for _, release := range libraryIndex.Libraries {
+		repositoryURL, err := url.Parse(release.Repository)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid repository URL %s of library %s: %s", release.Repository, release.Name, err)
+		}
+		gitHost, _ := gitHostProviderForURL(repositoryURL)
+		normalizedRepositoryURL := gitHost.NormalizeURL(repositoryURL)
+		libraryIndex.repositories[strings.ToLower(release.Name)] = normalizedRepositoryURL.String()
+		libraryIndex.names[normalizedRepositoryURL.String()] = release.Name
+		libraryIndex.releases[release.Name] = append(libraryIndex.releases[release.Name], release)
+	}
+
+	return &libraryIndex, nil
+}
+
+// repositoryForName returns the normalized URL of the repository of the library with the name, compared
+// case-insensitively, and whether the library is in the index.
+func (libraryIndex *libraryIndexType) repositoryForName(name string) (string, bool) {
+	repository, found := libraryIndex.repositories[strings.ToLower(name)]
+	return repository, found
+}
+
+// nameForRepository returns the name of the library at the normalized repository URL and whether the library is in the
+// index.
+func (libraryIndex *libraryIndexType) nameForRepository(normalizedURL string) (string, bool) {
+	name, found := libraryIndex.names[normalizedURL]
+	return name, found
+}
+
+// releasesForName returns the releases of the library with the name. Unlike the library name uniqueness check,
+// dependencies are resolved by exact name.
+func (libraryIndex *libraryIndexType) releasesForName(name string) []libraryIndexReleaseType {
+	return libraryIndex.releases[name]
+}
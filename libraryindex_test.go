@@ -0,0 +1,78 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseLibraryIndex(t *testing.T) {
+	testTables := []struct {
+		testName        string
+		rawLibraryIndex string
+	}{
+		{
+			"library_index.json",
+			`{"libraries": [
+				{"name": "Servo", "version": "1.1.0", "repository": "https://github.com/arduino-libraries/Servo.git"},
+				{"name": "Servo", "version": "1.1.1", "repository": "https://github.com/arduino-libraries/Servo.git"},
+				{"name": "Foo Bar", "version": "1.0.0", "repository": "https://gitlab.com/foo/bar/-/tree/main"}
+			]}`,
+		},
+		{
+			"Index source",
+			"https://github.com/arduino-libraries/Servo.git|Arduino|Servo\n\nhttps://gitlab.com/foo/bar|Contributed|Foo Bar\n",
+		},
+	}
+
+	for _, testTable := range testTables {
+		libraryIndex, err := parseLibraryIndex([]byte(testTable.rawLibraryIndex))
+		require.Nil(t, err, testTable.testName)
+
+		repository, found := libraryIndex.repositoryForName("Servo")
+		assert.True(t, found, testTable.testName)
+		assert.Equal(t, "https://github.com/arduino-libraries/Servo.git", repository, testTable.testName)
+
+		repository, found = libraryIndex.repositoryForName("foo BAR")
+		assert.True(t, found, "Names are compared case-insensitively")
+		assert.Equal(t, "https://gitlab.com/foo/bar.git", repository, "Repository URL is normalized")
+
+		_, found = libraryIndex.repositoryForName("Baz")
+		assert.False(t, found, testTable.testName)
+
+		assert.NotEmpty(t, libraryIndex.releasesForName("Servo"), testTable.testName)
+		assert.Empty(t, libraryIndex.releasesForName("servo"), "Releases are found by exact name")
+	}
+
+	libraryIndex, err := parseLibraryIndex([]byte(`{"libraries": [{"name": "Foo", "version": "1.0.0", "repository": "https://github.com/foo/foo.git", "dependencies": [{"name": "Servo", "version": ">=1.0.0"}]}]}`))
+	require.Nil(t, err)
+	assert.Equal(t, []libraryIndexDependencyType{{Name: "Servo", Version: ">=1.0.0"}}, libraryIndex.releasesForName("Foo")[0].Dependencies)
+
+	_, err = parseLibraryIndex([]byte(`{"libraries": [`))
+	assert.NotNil(t, err, "Invalid JSON")
+
+	_, err = parseLibraryIndex([]byte("https://github.com/foo/bar|Contributed\n"))
+	assert.NotNil(t, err, "Invalid index source line")
+}
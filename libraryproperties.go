@@ -0,0 +1,153 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	properties "github.com/arduino/go-properties-orderedmap"
+)
+
+// libraryPropertiesFindingLevelType is the type of the severity of a library.properties finding.
+type libraryPropertiesFindingLevelType string
+
+const (
+	// ErrorLevel means the library.properties field violates the library specification.
+	ErrorLevel libraryPropertiesFindingLevelType = "error"
+	// WarningLevel means the library.properties field is valid, but not recommended.
+	WarningLevel libraryPropertiesFindingLevelType = "warning"
+)
+
+// libraryPropertiesFindingType is the type of the data for a problem found in a library.properties field.
+type libraryPropertiesFindingType struct {
+	Field   string                            `json:"field"`   // library.properties field name.
+	Level   libraryPropertiesFindingLevelType `json:"level"`   // Severity of the problem.
+	Message string                            `json:"message"` // Description of the problem.
+}
+
+// requiredLibraryPropertiesFields are the library.properties fields required by the library specification, in the
+// order they are checked.
+var requiredLibraryPropertiesFields = []string{"name", "version", "author", "maintainer", "sentence", "paragraph", "category", "url", "architectures"}
+
+// libraryCategories are the allowed values of the library.properties `category` field.
+var libraryCategories = []string{
+	"Display",
+	"Communication",
+	"Signal Input/Output",
+	"Sensors",
+	"Device Control",
+	"Timing",
+	"Data Storage",
+	"Data Processing",
+	"Other",
+	"Uncategorized",
+}
+
+// dependencyRegexp matches a `depends` field entry, which is a library name optionally followed by a version constraint
+// in parentheses.
+var dependencyRegexp = regexp.MustCompile(`^([^()]*[^()\s])\s*(?:\((.*)\))?$`)
+
+// dependencyType is the type of the data for a library dependency declared in the library.properties `depends` field.
+type dependencyType struct {
+	Name       string            // Name of the library dependency.
+	Constraint versionConstraint // Constraint on the dependency version. nil if any version is allowed.
+}
+
+// validateLibraryProperties checks the library.properties fields against the library specification.
+func validateLibraryProperties(libraryProperties *properties.Map) []libraryPropertiesFindingType {
+	var findings []libraryPropertiesFindingType
+	addFinding := func(field string, level libraryPropertiesFindingLevelType, format string, a ...interface{}) {
+		findings = append(findings, libraryPropertiesFindingType{Field: field, Level: level, Message: fmt.Sprintf(format, a...)})
+	}
+
+	for _, field := range requiredLibraryPropertiesFields {
+		value, ok := libraryProperties.GetOk(field)
+		if !ok {
+			addFinding(field, ErrorLevel, "Required field is missing.")
+			continue
+		}
+		if strings.TrimSpace(value) == "" && field != "paragraph" {
+			addFinding(field, ErrorLevel, "Required field is empty.")
+			continue
+		}
+
+		switch field {
+		case "version":
+			if _, err := parseVersion(value); err != nil {
+				addFinding(field, ErrorLevel, "Value `%s` is not compliant with the semver specification.", value)
+			}
+		case "category":
+			if value == "Uncategorized" {
+				addFinding(field, WarningLevel, "Category `Uncategorized` is only intended for libraries with an invalid category. Use one of the other categories.")
+			} else if !slices.Contains(libraryCategories, value) {
+				addFinding(field, ErrorLevel, "Value `%s` is not a valid category. The valid categories are: %s.", value, strings.Join(libraryCategories, ", "))
+			}
+		case "url":
+			if urlObject, err := url.Parse(value); err != nil || (urlObject.Scheme != "http" && urlObject.Scheme != "https") || urlObject.Host == "" {
+				addFinding(field, ErrorLevel, "Value `%s` is not a valid HTTP(S) URL.", value)
+			}
+		case "architectures":
+			for _, architecture := range strings.Split(value, ",") {
+				if strings.TrimSpace(architecture) == "" {
+					addFinding(field, ErrorLevel, "Value `%s` contains an empty architecture.", value)
+					break
+				}
+			}
+		}
+	}
+
+	if depends, ok := libraryProperties.GetOk("depends"); ok && strings.TrimSpace(depends) != "" {
+		if _, err := parseDepends(depends); err != nil {
+			addFinding("depends", ErrorLevel, "%s", err)
+		}
+	}
+
+	return findings
+}
+
+// parseDepends parses the value of the library.properties `depends` field.
+func parseDepends(depends string) ([]dependencyType, error) {
+	var dependencies []dependencyType
+	for _, entry := range strings.Split(depends, ",") {
+		entry = strings.TrimSpace(entry)
+		match := dependencyRegexp.FindStringSubmatch(entry)
+		if match == nil {
+			return nil, fmt.Errorf("Dependency `%s` is not in the format `<library name>` or `<library name> (<version constraint>)`", entry)
+		}
+
+		dependency := dependencyType{Name: match[1]}
+		if strings.Contains(entry, "(") {
+			constraint, err := parseVersionConstraint(match[2])
+			if err != nil {
+				return nil, fmt.Errorf("Dependency `%s` has an invalid version constraint: %s", entry, err)
+			}
+			dependency.Constraint = constraint
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	return dependencies, nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"testing"
+
+	properties "github.com/arduino/go-properties-orderedmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_validateLibraryProperties(t *testing.T) {
+	validLibraryProperties := map[string]string{
+		"name":          "Foo",
+		"version":       "1.0.0",
+		"author":        "Jane Doe",
+		"maintainer":    "Jane Doe <jane@example.com>",
+		"sentence":      "A library.",
+		"paragraph":     "",
+		"category":      "Sensors",
+		"url":           "https://github.com/foo/bar",
+		"architectures": "avr,samd",
+		"depends":       "Servo, Adafruit GFX Library (>=1.0.0 && <2.0.0)",
+	}
+
+	testTables := []struct {
+		testName         string
+		changes          map[string]string // Changed field values. An empty value removes the field.
+		expectedFindings []libraryPropertiesFindingType
+	}{
+		{"Valid", nil, nil},
+		{
+			"Missing fields",
+			map[string]string{"author": "", "paragraph": ""},
+			[]libraryPropertiesFindingType{
+				{Field: "author", Level: ErrorLevel, Message: "Required field is missing."},
+				{Field: "paragraph", Level: ErrorLevel, Message: "Required field is missing."},
+			},
+		},
+		{
+			"Empty field",
+			map[string]string{"sentence": " "},
+			[]libraryPropertiesFindingType{{Field: "sentence", Level: ErrorLevel, Message: "Required field is empty."}},
+		},
+		{
+			"Invalid version",
+			map[string]string{"version": "1.0.0.0"},
+			[]libraryPropertiesFindingType{{Field: "version", Level: ErrorLevel, Message: "Value `1.0.0.0` is not compliant with the semver specification."}},
+		},
+		{
+			"Invalid category",
+			map[string]string{"category": "Sensor"},
+			[]libraryPropertiesFindingType{{Field: "category", Level: ErrorLevel, Message: "Value `Sensor` is not a valid category. The valid categories are: Display, Communication, Signal Input/Output, Sensors, Device Control, Timing, Data Storage, Data Processing, Other, Uncategorized."}},
+		},
+		{
+			"Uncategorized",
+			map[string]string{"category": "Uncategorized"},
+			[]libraryPropertiesFindingType{{Field: "category", Level: WarningLevel, Message: "Category `Uncategorized` is only intended for libraries with an invalid category. Use one of the other categories."}},
+		},
+		{
+			"Invalid URL",
+			map[string]string{"url": "github.com/foo/bar"},
+			[]libraryPropertiesFindingType{{Field: "url", Level: ErrorLevel, Message: "Value `github.com/foo/bar` is not a valid HTTP(S) URL."}},
+		},
+		{
+			"Empty architecture",
+			map[string]string{"architectures": "avr,,samd"},
+			[]libraryPropertiesFindingType{{Field: "architectures", Level: ErrorLevel, Message: "Value `avr,,samd` contains an empty architecture."}},
+		},
+		{
+			"Invalid depends",
+			map[string]string{"depends": "Servo (1.0.0)"},
+			[]libraryPropertiesFindingType{{Field: "depends", Level: ErrorLevel, Message: "Dependency `Servo (1.0.0)` has an invalid version constraint: expected an operator at `1.0.0`"}},
+		},
+		{
+			"Malformed depends",
+			map[string]string{"depends": "Servo (>=1.0.0"},
+			[]libraryPropertiesFindingType{{Field: "depends", Level: ErrorLevel, Message: "Dependency `Servo (>=1.0.0` is not in the format `<library name>` or `<library name> (<version constraint>)`"}},
+		},
+	}
+
+	for _, testTable := range testTables {
+		libraryProperties := properties.NewMap()
+		for _, field := range []string{"name", "version", "author", "maintainer", "sentence", "paragraph", "category", "url", "architectures", "depends"} {
+			value, changed := testTable.changes[field]
+			if !changed {
+				value = validLibraryProperties[field]
+			} else if value == "" {
+				continue
+			}
+			libraryProperties.Set(field, value)
+		}
+
+		assert.Equal(t, testTable.expectedFindings, validateLibraryProperties(libraryProperties), testTable.testName)
+	}
+}
+
+func Test_parseDepends(t *testing.T) {
+	dependencies, err := parseDepends("Servo, Adafruit GFX Library (>=1.0.0), Foo( =2.0.0 )")
+	require.Nil(t, err)
+	require.Len(t, dependencies, 3)
+	assert.Equal(t, dependencyType{Name: "Servo"}, dependencies[0])
+	assert.Equal(t, "Adafruit GFX Library", dependencies[1].Name)
+	assert.Equal(t, versionComparisonType{operator: ">=", version: versionType{major: 1}}, dependencies[1].Constraint)
+	assert.Equal(t, "Foo", dependencies[2].Name)
+	assert.Equal(t, versionComparisonType{operator: "=", version: versionType{major: 2}}, dependencies[2].Constraint)
+
+	for _, depends := range []string{"Servo,", "(>=1.0.0)", "Servo (>=1.0.0", "Servo ()", "Servo (>=1.0.0) Foo"} {
+		_, err := parseDepends(depends)
+		assert.NotNil(t, err, depends)
+	}
+}
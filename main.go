@@ -24,6 +24,7 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -31,7 +32,10 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/sourcegraph/go-diff/diff"
 	"gopkg.in/yaml.v3"
@@ -40,34 +44,6 @@ import (
 	properties "github.com/arduino/go-properties-orderedmap"
 )
 
-// Git hosts that are supported for library repositories.
-var supportedHosts []string = []string{
-	"bitbucket.org",
-	"git.antares.id",
-	"github.com",
-	"gitlab.com",
-}
-
-// Libraries under these organizations will have the "Arduino" type and be linted with Arduino Lint in the "official" setting.
-var officialOrganizations []string = []string{
-	"github.com/arduino",
-	"github.com/arduino-libraries",
-	"github.com/bcmi-labs",
-	"github.com/vidor-libraries",
-}
-
-// Libraries under these organizations will have the "Partner" type.
-var partnerOrganizations []string = []string{
-	"github.com/Azure",
-	"github.com/ms-iot",
-	"github.com/ameltech",
-}
-
-// Libraries under these organizations will have the "Recommended" type.
-var recommendedOrganizations []string = []string{
-	"github.com/adafruit",
-}
-
 // accessType is the type of the access control level.
 type accessType string
 
@@ -85,10 +61,17 @@ const (
 
 // accessDataType is the type of the access control data.
 type accessDataType struct {
-	Access    accessType `yaml:"access"`    // Access level.
-	Host      string     `yaml:"host"`      // Account host (e.g., `github.com`).
-	Name      string     `yaml:"name"`      // User or organization account name.
-	Reference string     `yaml:"reference"` // URL that provides additional information about the access control entry.
+	Access     accessType `yaml:"access" json:"access"`                   // Access level.
+	Host       string     `yaml:"host" json:"host"`                       // Account host (e.g., `github.com`).
+	Name       string     `yaml:"name" json:"name"`                       // User or organization account name.
+	Repository string     `yaml:"repository" json:"repository,omitempty"` // Name of the single repository of the account the entry applies to.
+	Pattern    string     `yaml:"pattern" json:"pattern,omitempty"`       // Glob pattern matched against the `<host>/<owner>/<repository>` path of library repositories.
+	Regex      string     `yaml:"regex" json:"regex,omitempty"`           // Regular expression matched against the complete `<host>/<owner>/<repository>` path of library repositories.
+	Reference  string     `yaml:"reference" json:"reference"`             // URL that provides additional information about the access control entry.
+	Since      *time.Time `yaml:"since" json:"since,omitempty"`           // Time the entry takes effect. If not set, the entry is in effect from the start.
+	Until      *time.Time `yaml:"until" json:"until,omitempty"`           // Time the entry expires. If not set, the entry never expires.
+
+	compiledRegex *regexp.Regexp // Compiled form of Regex.
 }
 
 // request is the type of the request data.
@@ -97,11 +80,56 @@ type request struct {
 	Type                             string           `json:"type"`                             // Request type.
 	ArduinoLintLibraryManagerSetting string           `json:"arduinoLintLibraryManagerSetting"` // Argument to pass to Arduino Lint's --library-manager flag.
 	Submissions                      []submissionType `json:"submissions"`                      // Data for submitted libraries.
+	Removals                         []removalType    `json:"removals"`                         // Data for libraries removed by a removal request.
 	IndexEntry                       string           `json:"indexEntry"`                       // Entry that will be made to the Library Manager index source file when the submission is accepted.
 	IndexerLogsURLs                  string           `json:"indexerLogsURLs"`                  // List of URLs where the logs from the Library Manager indexer for each submission are available for view.
+	InternalError                    *internalError   `json:"internalError"`                    // Problem in the parser or its environment that prevented the request from being processed. Only set when the conclusion is "error".
+	ExpiredAccessEntries             []accessDataType `json:"expiredAccessEntries"`             // Access control entries that have expired and can be removed from the access control file.
 	Error                            string           `json:"error"`                            // Error message.
 }
 
+// internalErrorCodeType is the type of the stable identifiers for the kinds of internal errors.
+type internalErrorCodeType string
+
+const (
+	// DiffParseError means the request diff could not be parsed.
+	DiffParseError internalErrorCodeType = "diff-parse"
+	// ListParseError means a line of the list file could not be parsed.
+	ListParseError internalErrorCodeType = "list-parse"
+	// GitLsRemoteError means `git ls-remote` failed for a reason other than the URL not being a Git repository.
+	GitLsRemoteError internalErrorCodeType = "git-ls-remote"
+	// CloneFolderError means the temporary folder for the submission repository clone could not be created.
+	CloneFolderError internalErrorCodeType = "clone-folder"
+	// GitCloneError means the submission repository could not be cloned.
+	GitCloneError internalErrorCodeType = "git-clone"
+	// GitFetchTagsError means the submission repository's tags could not be fetched.
+	GitFetchTagsError internalErrorCodeType = "git-fetch-tags"
+	// GitTagError means the submission repository's latest tag could not be determined.
+	GitTagError internalErrorCodeType = "git-tag"
+	// HostAPIError means a request to the Git host's API failed.
+	HostAPIError internalErrorCodeType = "host-api"
+	// GitCheckoutError means the submission repository's latest tag could not be checked out.
+	GitCheckoutError internalErrorCodeType = "git-checkout"
+	// ContentScanError means the files of the submission repository's release tag could not be read.
+	ContentScanError internalErrorCodeType = "content-scan"
+)
+
+// internalError is the type of the data for a problem in the parser or its environment, as opposed to a problem with the
+// request.
+type internalError struct {
+	Code    internalErrorCodeType `json:"code"`    // Stable identifier for the kind of error.
+	Message string                `json:"message"` // Error message.
+}
+
+// newInternalError returns an internal error of the given kind, with a message describing the failed operation.
+func newInternalError(code internalErrorCodeType, format string, a ...interface{}) *internalError {
+	return &internalError{Code: code, Message: fmt.Sprintf(format, a...)}
+}
+
+func (err *internalError) Error() string {
+	return fmt.Sprintf("%s: %s", err.Code, err.Message)
+}
+
 // submissionType is the type of the data for each individual library submitted in the request.
 type submissionType struct {
 	SubmissionURL  string `json:"submissionURL"`  // Library repository URL as submitted by user. Used to identify the submission to the user.
@@ -109,21 +137,85 @@ type submissionType struct {
 	RepositoryName string `json:"repositoryName"` // Name of the submission's repository.
 	Name           string `json:"name"`           // Library name.
 	Official       bool   `json:"official"`       // Whether the library is official.
-	Tag            string `json:"tag"`            // Name of the submission repository's latest tag, which is used as the basis for the index entry and validation.
+	Tag            string `json:"tag"`            // Name of the submission repository's release tag, which is used as the basis for the index entry and validation.
 	Error          string `json:"error"`          // Error message.
+
+	OldURL                    string                         `json:"oldURL,omitempty"`                    // For modification requests, the URL replaced by the submission.
+	NewURL                    string                         `json:"newURL,omitempty"`                    // For modification requests, the URL replacing OldURL.
+	ModificationFlags         []string                       `json:"modificationFlags,omitempty"`         // For modification requests, identifiers of the policy-sensitive changes made by the submission.
+	Warnings                  []string                       `json:"warnings,omitempty"`                  // Messages about aspects of the submission that need review.
+	TagSelection              *tagSelectionType              `json:"tagSelection,omitempty"`              // Explanation of how Tag was selected.
+	LibraryPropertiesFindings []libraryPropertiesFindingType `json:"libraryPropertiesFindings,omitempty"` // Problems found in the library.properties fields of the release.
+	DependencyFindings        []dependencyFindingType        `json:"dependencyFindings,omitempty"`        // Problems found resolving the library's dependencies against the Library Manager index.
+	SimilarNames              []similarNameType              `json:"similarNames,omitempty"`              // Libraries in the Library Manager index with names similar to Name.
+	ContentFindings           []contentFindingType           `json:"contentFindings,omitempty"`           // Problems found in the content of the release tag.
+	Releases                  []releaseCheckType             `json:"releases,omitempty"`                  // In deep mode, the indexability of the release of each version tag.
+	File                      string                         `json:"file"`                                // Path of the list file.
+	Line                      int                            `json:"line"`                                // Number of the line of the submission URL in the list file, for use in review annotations.
 }
 
 // Command line flags.
 // Path of the access control file, relative to repopath.
 var accesslistArgument = flag.String("accesslist", "", "")
+
+// Path of the diff file, or `-` to read the diff from stdin.
 var diffPathArgument = flag.String("diffpath", "", "")
+
+// Format of the diff file: `unified` or `github-json` (the GitHub pull request files API response).
+var diffFormatArgument = flag.String("diffformat", unifiedDiffFormat, "")
+
+// Path of the library types configuration file, relative to repopath. The built-in types are used if not set.
+var typesConfigArgument = flag.String("typesconfig", "", "")
+
+// Path of the Git hosts configuration file, relative to repopath. The built-in Git hosts are used if not set.
+var gitHostsConfigArgument = flag.String("githostsconfig", "", "")
+
+// Path of the library name policy file, relative to repopath. The default policy is used if not set.
+var namePolicyArgument = flag.String("namepolicy", "", "")
 var repoPathArgument = flag.String("repopath", "", "")
 var listNameArgument = flag.String("listname", "", "")
 
 // GitHub username of the user making the submission.
 var submitterArgument = flag.String("submitter", "", "")
 
+// Path of the Library Manager index data (library_index.json or the index source file). Optional.
+var indexArgument = flag.String("index", "", "")
+
+// Maximum number of submissions to process concurrently.
+var jobsArgument = flag.Int("jobs", 4, "")
+
+// Path of the file to write the request data to. The data is written to stdout if not set.
+var outputArgument = flag.String("output", "", "")
+
+// Path of the fixtures folder. If set, HTTP requests and Git remote operations use the fixtures instead of the network.
+var fixturesArgument = flag.String("fixtures", "", "")
+
+// Whether the release tag is selected from the tags of the repository's Git host releases when it has any.
+var releasesArgument = flag.Bool("releases", false, "")
+
+// Whether to check the releases of all version tags, in addition to the release tag.
+var deepArgument = flag.Bool("deep", false, "")
+
+// Git client implementation: `exec` (the git executable) or `go-git` (in process, no git executable needed).
+var gitClientArgument = flag.String("gitclient", "exec", "")
+
+// httpClient is the client used for all HTTP requests.
+var httpClient = &http.Client{}
+
 func main() {
+	if gitHostProvidersError != nil {
+		errorExit(fmt.Sprintf("Invalid Git host configuration: %s", gitHostProvidersError))
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "validate-accesslist" {
+		validateAccessListCommand(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "check-list" {
+		checkListCommand(os.Args[2:])
+		return
+	}
+
 	// Validate flag input.
 	flag.Parse()
 
@@ -135,6 +227,10 @@ func main() {
 		errorExit("--diffpath flag is required")
 	}
 
+	if *diffFormatArgument != unifiedDiffFormat && *diffFormatArgument != gitHubJSONDiffFormat {
+		errorExit(fmt.Sprintf("--diffformat flag must be %s or %s", unifiedDiffFormat, gitHubJSONDiffFormat))
+	}
+
 	if *repoPathArgument == "" {
 		errorExit("--repopath flag is required")
 	}
@@ -147,16 +243,48 @@ func main() {
 		errorExit("--submitter flag is required")
 	}
 
+	if *jobsArgument < 1 {
+		errorExit("--jobs flag must be at least 1")
+	}
+
+	gitClientFactory, ok := gitClientFactories[*gitClientArgument]
+	if !ok {
+		errorExit("--gitclient flag must be exec or go-git")
+	}
+	gitClient = gitClientFactory()
+
+	if *gitHostsConfigArgument != "" {
+		rawGitHostsConfiguration, err := paths.New(*repoPathArgument, *gitHostsConfigArgument).ReadFile()
+		if err != nil {
+			errorExit(fmt.Sprintf("Unable to read Git hosts configuration file: %s", err))
+		}
+		gitHostProviders, err = parseGitHostsConfiguration(rawGitHostsConfiguration)
+		if err != nil {
+			errorExit(fmt.Sprintf("Git hosts configuration file has invalid format:\n\n%s", err))
+		}
+	}
+
+	if *fixturesArgument != "" {
+		fixtures, err := loadFixtures(paths.New(*fixturesArgument))
+		if err != nil {
+			errorExit(fmt.Sprintf("Unable to load fixtures: %s", err))
+		}
+		defer fixtures.remove()
+		exitCleanups = append(exitCleanups, fixtures.remove)
+		useFixtures(fixtures)
+	}
+
 	accesslistPath := paths.New(*repoPathArgument, *accesslistArgument)
 	exist, err := accesslistPath.ExistCheck()
 	if !exist {
 		errorExit("Access control file not found")
 	}
 
-	diffPath := paths.New(*diffPathArgument)
-	exist, err = diffPath.ExistCheck()
-	if !exist {
-		errorExit("diff file not found")
+	if *diffPathArgument != stdinDiffPath {
+		exist, err = paths.New(*diffPathArgument).ExistCheck()
+		if !exist {
+			errorExit("diff file not found")
+		}
 	}
 
 	listPath := paths.New(*repoPathArgument, *listNameArgument)
@@ -165,25 +293,81 @@ func main() {
 		errorExit(fmt.Sprintf("list file %s not found", listPath))
 	}
 
+	// The list is read once up front for use by all the concurrently processed submissions.
+	listLines, err := listPath.ReadFileAsLines()
+	if err != nil {
+		errorExit(fmt.Sprintf("Unable to read list file: %s", err))
+	}
+
 	rawAccessList, err := accesslistPath.ReadFile()
 	if err != nil {
-		panic(err)
+		errorExit(fmt.Sprintf("Unable to read access control file: %s", err))
 	}
 
 	// Unmarshal access control file.
 	var accessList []accessDataType
 	err = yaml.Unmarshal(rawAccessList, &accessList)
+	if err == nil {
+		err = compileAccessPatterns(accessList)
+	}
 	if err != nil {
 		errorExit(fmt.Sprintf("Access control file has invalid format:\n\n%s", err))
 	}
 
+	typesConfiguration := defaultTypesConfiguration
+	if *typesConfigArgument != "" {
+		typesConfigPath := paths.New(*repoPathArgument, *typesConfigArgument)
+		exist, err = typesConfigPath.ExistCheck()
+		if !exist {
+			errorExit("Types configuration file not found")
+		}
+
+		rawTypesConfiguration, err := typesConfigPath.ReadFile()
+		if err != nil {
+			errorExit(fmt.Sprintf("Unable to read types configuration file: %s", err))
+		}
+
+		typesConfiguration, err = parseTypesConfiguration(rawTypesConfiguration)
+		if err != nil {
+			errorExit(fmt.Sprintf("Types configuration file has invalid format:\n\n%s", err))
+		}
+	}
+
+	namePolicy := defaultNamePolicy
+	if *namePolicyArgument != "" {
+		rawNamePolicy, err := paths.New(*repoPathArgument, *namePolicyArgument).ReadFile()
+		if err != nil {
+			errorExit(fmt.Sprintf("Unable to read name policy file: %s", err))
+		}
+		namePolicy, err = parseNamePolicy(rawNamePolicy)
+		if err != nil {
+			errorExit(fmt.Sprintf("Name policy file has invalid format:\n\n%s", err))
+		}
+	}
+
+	var libraryIndex *libraryIndexType
+	if *indexArgument != "" {
+		rawLibraryIndex, err := paths.New(*indexArgument).ReadFile()
+		if err != nil {
+			errorExit(fmt.Sprintf("Unable to read index file: %s", err))
+		}
+		libraryIndex, err = parseLibraryIndex(rawLibraryIndex)
+		if err != nil {
+			errorExit(fmt.Sprintf("Index file has invalid format:\n\n%s", err))
+		}
+	}
+
 	var req request
-	var submissionURLs []string
+	var submissions []listChangeType
+	var removals []listChangeType
+
+	// Only the entries in effect at the time of the request are applied.
+	accessList, req.ExpiredAccessEntries = partitionAccessList(accessList, time.Now())
 
 	// Determine access level of submitter.
 	var submitterAccess accessType = Default
 	for _, accessData := range accessList {
-		if accessData.Host == "github.com" && *submitterArgument == accessData.Name {
+		if accessData.scope() == ownerAccessScope && accessData.Host == "github.com" && *submitterArgument == accessData.Name {
 			submitterAccess = accessData.Access
 			if submitterAccess == Deny {
 				req.Conclusion = "declined"
@@ -196,30 +380,51 @@ func main() {
 
 	if req.Error == "" {
 		// Parse the PR diff.
-		rawDiff, err := diffPath.ReadFile()
+		rawDiff, err := readDiff(*diffPathArgument, os.Stdin)
 		if err != nil {
-			panic(err)
+			errorExit(fmt.Sprintf("Unable to read diff file: %s", err))
+		}
+		if *diffFormatArgument == gitHubJSONDiffFormat {
+			req.Type, req.Error, req.ArduinoLintLibraryManagerSetting, submissions, removals, err = parseGitHubJSONDiff(rawDiff, *listNameArgument)
+		} else {
+			req.Type, req.Error, req.ArduinoLintLibraryManagerSetting, submissions, removals, err = parseDiff(rawDiff, *listNameArgument)
+		}
+		errors.As(err, &req.InternalError)
+	}
+
+	// Gather the data for review of removals.
+	if req.Type == "removal" {
+		for _, removal := range removals {
+			req.Removals = append(req.Removals, populateRemoval(removal, *submitterArgument, libraryIndex))
 		}
-		req.Type, req.Error, req.ArduinoLintLibraryManagerSetting, submissionURLs = parseDiff(rawDiff, *listNameArgument)
 	}
 
 	// Process the submissions.
+	submissionResults := processSubmissions(submissions, *jobsArgument, func(listChange listChangeType) (submissionType, string, bool, error) {
+		return populateSubmission(listChange, listLines, typesConfiguration, accessList, submitterAccess, libraryIndex, submissionOptionsType{preferReleases: *releasesArgument, deep: *deepArgument, namePolicy: &namePolicy})
+	})
 	var indexEntries []string
 	var indexerLogsURLs []string
 	allowedSubmissions := false
-	for _, submissionURL := range submissionURLs {
-		submission, indexEntry, allowed := populateSubmission(submissionURL, listPath, accessList, submitterAccess)
-		req.Submissions = append(req.Submissions, submission)
-		indexEntries = append(indexEntries, indexEntry)
-		indexerLogsURLs = append(indexerLogsURLs, indexerLogsURL(submission.NormalizedURL))
-		if allowed {
+	for _, submissionResult := range submissionResults {
+		req.Submissions = append(req.Submissions, submissionResult.submission)
+		indexEntries = append(indexEntries, submissionResult.indexEntry)
+		indexerLogsURLs = append(indexerLogsURLs, indexerLogsURL(submissionResult.submission.NormalizedURL))
+		if submissionResult.allowed {
 			allowedSubmissions = true
 		}
+		if req.InternalError == nil {
+			errors.As(submissionResult.err, &req.InternalError)
+		}
 	}
-	if len(submissionURLs) > 0 && !allowedSubmissions {
+	if len(submissions) > 0 && !allowedSubmissions {
 		// If none of the submissions are allowed, decline the request.
 		req.Conclusion = "declined"
 	}
+	if req.InternalError != nil {
+		// The request could not be fully processed, so a maintainer must investigate.
+		req.Conclusion = "error"
+	}
 
 	// Check for duplicates within the submission itself.
 	submissionURLMap := make(map[string]bool)
@@ -250,54 +455,121 @@ func main() {
 		panic(err)
 	}
 
-	fmt.Println(marshaledRequest.String())
+	if *outputArgument == "" {
+		fmt.Println(marshaledRequest.String())
+	} else {
+		err = paths.New(*outputArgument).WriteFile(marshaledRequest.Bytes())
+		if err != nil {
+			errorExit(fmt.Sprintf("Unable to write output file: %s", err))
+		}
+	}
 }
 
-// errorExit prints the error message in a standardized format and exits with status 1.
+// exitCleanups are the functions run by errorExit before exiting, since deferred functions are not run by os.Exit.
+var exitCleanups []func()
+
+// errorExit prints the error message in a standardized format to stderr, runs the exit cleanups, and exits with status 1.
 func errorExit(message string) {
-	fmt.Printf("ERROR: %s\n", message)
+	fmt.Fprintf(os.Stderr, "ERROR: %s\n", message)
+	for _, cleanup := range exitCleanups {
+		cleanup()
+	}
 	os.Exit(1)
 }
 
-// parseDiff parses the request diff and returns the request type, request error, `arduino-lint --library-manager` setting, and list of submission URLs.
-func parseDiff(rawDiff []byte, listName string) (string, string, string, []string) {
-	var submissionURLs []string
+// stdinDiffPath is the --diffpath value for reading the diff from stdin.
+const stdinDiffPath = "-"
+
+// readDiff returns the diff data from the file at the path, or from stdin if the path is stdinDiffPath.
+func readDiff(diffPath string, stdin io.Reader) ([]byte, error) {
+	if diffPath == stdinDiffPath {
+		return io.ReadAll(stdin)
+	}
+
+	return paths.New(diffPath).ReadFile()
+}
 
+// listChangeType is the type of the data for a line added to or removed from the list by the request.
+type listChangeType struct {
+	URL         string // URL on the line.
+	ReplacedURL string // For added lines, the URL on the removed line the added line replaces, if any.
+	File        string // Path of the list file.
+	Line        int    // Line number in the new version of the file for added lines, or in the old version for removed lines.
+}
+
+// noNewlineMarker is the line diffs use to indicate the file does not end in a newline.
+const noNewlineMarker = "\\ No newline at end of file"
+
+// noNewlineError is the request error for a PR that removes the final newline from a file.
+const noNewlineError = "Pull request removes newline from the end of a file.%0APlease add a blank line to the end of the file."
+
+// parseDiff parses the request diff and returns the request type, request error, `arduino-lint --library-manager` setting, list of submissions, list of removals, and internal error.
+func parseDiff(rawDiff []byte, listName string) (string, string, string, []listChangeType, []listChangeType, error) {
 	// Check if the PR has removed the final newline from a file, which would cause a spurious diff for the next PR if merged.
 	// Unfortunately, the diff package does not have this capability (only to detect missing newline in the original file).
-	if bytes.Contains(rawDiff, []byte("\\ No newline at end of file")) {
-		return "invalid", "Pull request removes newline from the end of a file.%0APlease add a blank line to the end of the file.", "", nil
+	if bytes.Contains(rawDiff, []byte(noNewlineMarker)) {
+		return "invalid", noNewlineError, "", nil, nil, nil
 	}
 
 	diffs, err := diff.ParseMultiFileDiff(rawDiff)
 	if err != nil {
-		panic(err)
+		return "", "", "", nil, nil, newInternalError(DiffParseError, "Unable to parse diff: %s", err)
 	}
 
+	return classifyDiff(diffs, listName)
+}
+
+// classifyDiff determines the request type from the parsed request diff and returns the request type, request error,
+// `arduino-lint --library-manager` setting, list of submissions, list of removals, and internal error.
+func classifyDiff(diffs []*diff.FileDiff, listName string) (string, string, string, []listChangeType, []listChangeType, error) {
+	var submissions []listChangeType
+	var removals []listChangeType
+
 	if (len(diffs) != 1) || (diffs[0].OrigName[2:] != listName) || (diffs[0].OrigName[2:] != diffs[0].NewName[2:]) { // Git diffs have a a/ or b/ prefix on file names.
 		// This is not a Library Manager submission.
-		return "other", "", "", nil
+		return "other", "", "", nil, nil, nil
 	}
 
 	var addedCount int
 	var deletedCount int
-	// Get the added URLs from the diff
-	for _, hunk := range diffs[0].Hunks {
-		hunkBody := string(hunk.Body)
+	// Indexes of the submissions and removals from each hunk.
+	hunkSubmissionIndexes := make([][]int, len(diffs[0].Hunks))
+	hunkRemovalIndexes := make([][]int, len(diffs[0].Hunks))
+	// Get the added and removed URLs from the diff
+	for hunkIndex, hunk := range diffs[0].Hunks {
+		hunkBody := strings.TrimSuffix(string(hunk.Body), "\n")
+		// Line numbers of the current line in the old and new versions of the file.
+		origLine := int(hunk.OrigStartLine)
+		newLine := int(hunk.NewStartLine)
 		for _, rawDiffLine := range strings.Split(hunkBody, "\n") {
 			diffLine := strings.TrimRight(rawDiffLine, " \t")
 			if len(diffLine) < 2 {
-				continue // Ignore blank lines.
+				// Ignore blank lines, but still count them.
+				if strings.HasPrefix(rawDiffLine, "+") {
+					newLine++
+				} else if strings.HasPrefix(rawDiffLine, "-") {
+					origLine++
+				} else {
+					origLine++
+					newLine++
+				}
+				continue
 			}
 
 			switch diffLine[0] {
 			case '+':
 				addedCount++
-				submissionURLs = append(submissionURLs, strings.TrimSpace(diffLine[1:]))
+				hunkSubmissionIndexes[hunkIndex] = append(hunkSubmissionIndexes[hunkIndex], len(submissions))
+				submissions = append(submissions, listChangeType{URL: strings.TrimSpace(diffLine[1:]), File: listName, Line: newLine})
+				newLine++
 			case '-':
 				deletedCount++
+				hunkRemovalIndexes[hunkIndex] = append(hunkRemovalIndexes[hunkIndex], len(removals))
+				removals = append(removals, listChangeType{URL: strings.TrimSpace(diffLine[1:]), File: listName, Line: origLine})
+				origLine++
 			default:
-				continue
+				origLine++
+				newLine++
 			}
 		}
 	}
@@ -317,160 +589,339 @@ func parseDiff(rawDiff []byte, listName string) (string, string, string, []strin
 		arduinoLintLibraryManagerSetting = "update"
 	}
 
-	return requestType, "", arduinoLintLibraryManagerSetting, submissionURLs
+	pairReplacedURLs(submissions, removals, hunkSubmissionIndexes, hunkRemovalIndexes)
+
+	return requestType, "", arduinoLintLibraryManagerSetting, submissions, removals, nil
+}
+
+// pairReplacedURLs determines which removed URL each submission replaces. URLs with the same repository name are paired,
+// first within each hunk and then across hunks. The remaining URLs in each hunk are then paired by position.
+func pairReplacedURLs(submissions []listChangeType, removals []listChangeType, hunkSubmissionIndexes [][]int, hunkRemovalIndexes [][]int) {
+	pairedRemovals := make(map[int]bool)
+
+	pairBy := func(submissionIndexes []int, removalIndexes []int, match func(submissionURL string, removedURL string) bool) {
+		for _, submissionIndex := range submissionIndexes {
+			if submissions[submissionIndex].ReplacedURL != "" {
+				continue
+			}
+			for _, removalIndex := range removalIndexes {
+				if !pairedRemovals[removalIndex] && match(submissions[submissionIndex].URL, removals[removalIndex].URL) {
+					submissions[submissionIndex].ReplacedURL = removals[removalIndex].URL
+					pairedRemovals[removalIndex] = true
+					break
+				}
+			}
+		}
+	}
+	sameRepositoryName := func(submissionURL string, removedURL string) bool {
+		submissionRepositoryName := repositoryNameFromURL(submissionURL)
+		return submissionRepositoryName != "" && strings.EqualFold(submissionRepositoryName, repositoryNameFromURL(removedURL))
+	}
+	anyURL := func(submissionURL string, removedURL string) bool { return true }
+
+	var allSubmissionIndexes []int
+	var allRemovalIndexes []int
+	for hunkIndex := range hunkSubmissionIndexes {
+		pairBy(hunkSubmissionIndexes[hunkIndex], hunkRemovalIndexes[hunkIndex], sameRepositoryName)
+		allSubmissionIndexes = append(allSubmissionIndexes, hunkSubmissionIndexes[hunkIndex]...)
+		allRemovalIndexes = append(allRemovalIndexes, hunkRemovalIndexes[hunkIndex]...)
+	}
+	pairBy(allSubmissionIndexes, allRemovalIndexes, sameRepositoryName)
+	for hunkIndex := range hunkSubmissionIndexes {
+		pairBy(hunkSubmissionIndexes[hunkIndex], hunkRemovalIndexes[hunkIndex], anyURL)
+	}
+}
+
+// repositoryNameFromURL returns the name of the repository at the URL, or an empty string if it can't be determined.
+func repositoryNameFromURL(rawURL string) string {
+	urlObject, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	pathElements := uRLPathElements(*urlObject)
+	if len(pathElements) == 0 {
+		return ""
+	}
+
+	return pathElements[len(pathElements)-1]
+}
+
+// submissionResultType is the type of the result of processing a submission.
+type submissionResultType struct {
+	submission submissionType // Data for the submitted library.
+	indexEntry string         // Index source entry for the submission.
+	allowed    bool           // Whether the submitter is allowed to make the submission.
+	err        error          // Internal error that prevented the submission from being processed.
+}
+
+// processSubmissions processes the submissions concurrently with the given number of workers, returning the results in
+// the same order as the submissions.
+func processSubmissions(submissions []listChangeType, jobs int, populate func(listChange listChangeType) (submissionType, string, bool, error)) []submissionResultType {
+	submissionResults := make([]submissionResultType, len(submissions))
+	submissionIndexes := make(chan int)
+	var waitGroup sync.WaitGroup
+	for worker := 0; worker < jobs && worker < len(submissions); worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for submissionIndex := range submissionIndexes {
+				submission, indexEntry, allowed, err := populate(submissions[submissionIndex])
+				submissionResults[submissionIndex] = submissionResultType{submission: submission, indexEntry: indexEntry, allowed: allowed, err: err}
+			}
+		}()
+	}
+
+	for submissionIndex := range submissions {
+		submissionIndexes <- submissionIndex
+	}
+	close(submissionIndexes)
+	waitGroup.Wait()
+
+	return submissionResults
+}
+
+// submissionOptionsType is the type of the optional behaviors of the submission checks.
+type submissionOptionsType struct {
+	preferReleases bool            // Select the release tag from the tags of the Git host's releases.
+	deep           bool            // Check the releases of all version tags.
+	namePolicy     *namePolicyType // Policy the library name must comply with. nil to skip the name policy checks.
 }
 
 // populateSubmission does the checks on the submission that aren't provided by Arduino Lint and gathers the necessary data on it.
-func populateSubmission(submissionURL string, listPath *paths.Path, accessList []accessDataType, submitterAccess accessType) (submissionType, string, bool) {
+func populateSubmission(listChange listChangeType, listLines []string, typesConfiguration []libraryTypeDataType, accessList []accessDataType, submitterAccess accessType, libraryIndex *libraryIndexType, options submissionOptionsType) (submissionType, string, bool, error) {
 	indexSourceSeparator := "|"
 	var submission submissionType
 
-	submission.SubmissionURL = submissionURL
+	submission.SubmissionURL = listChange.URL
+	submission.File = listChange.File
+	submission.Line = listChange.Line
+	if listChange.ReplacedURL != "" {
+		submission.OldURL = listChange.ReplacedURL
+		submission.NewURL = listChange.URL
+	}
 
 	// Normalize and validate submission URL.
 	submissionURLObject, err := url.Parse(submission.SubmissionURL)
 	if err != nil {
 		submission.Error = fmt.Sprintf("Invalid submission URL (%s)", err)
-		return submission, "", true
+		return submission, "", true, nil
 	}
 
 	// Check if URL is accessible.
-	httpResponse, err := http.Get(submissionURLObject.String())
+	httpResponse, err := httpClient.Get(submissionURLObject.String())
 	if err != nil {
 		submission.Error = fmt.Sprintf("Unable to load submission URL: %s", err)
-		return submission, "", true
+		return submission, "", true, nil
 	}
+	defer httpResponse.Body.Close()
 	if httpResponse.StatusCode != http.StatusOK {
 		submission.Error = "Unable to load submission URL. Is the repository public?"
-		return submission, "", true
+		return submission, "", true, nil
 	}
 
 	// Resolve redirects and normalize.
-	normalizedURLObject := normalizeURL(httpResponse.Request.URL)
+	gitHost, supportedGitHost := gitHostProviderForURL(httpResponse.Request.URL)
+	normalizedURLObject := gitHost.NormalizeURL(httpResponse.Request.URL)
 
 	submission.NormalizedURL = normalizedURLObject.String()
 
+	if submission.OldURL != "" {
+		flagOwnerChange(&submission, normalizedURLObject)
+	}
+
 	if submitterAccess != Allow {
-		// Check library repository owner access.
-		for _, accessData := range accessList {
-			ownerSlug := fmt.Sprintf("%s/%s", accessData.Host, accessData.Name)
-			if accessData.Access == Deny && uRLIsUnder(normalizedURLObject, []string{ownerSlug}) {
-				submission.Error = fmt.Sprintf("Library registry privileges for library repository owner `%s` have been revoked.%%0ASee: %s", ownerSlug, accessData.Reference)
-				return submission, "", false
-			}
+		// Check library repository access.
+		accessData, found := repositoryAccessData(normalizedURLObject, accessList)
+		if found && accessData.Access == Deny {
+			submission.Error = fmt.Sprintf("Library registry privileges for %s have been revoked.%%0ASee: %s", accessData.subject(), accessData.Reference)
+			return submission, "", false, nil
 		}
 	}
 
 	// Check if URL is from a supported Git host.
-	if !uRLIsUnder(normalizedURLObject, supportedHosts) {
+	if !supportedGitHost {
 		submission.Error = fmt.Sprintf("`%s` is not currently supported as a Git hosting website for Library Manager.%%0A%%0ASee: https://github.com/arduino/library-registry/blob/main/FAQ.md#what-are-the-requirements-for-a-library-to-be-added-to-library-manager", normalizedURLObject.Host)
-		return submission, "", true
+		return submission, "", true, nil
 	}
 
 	// Check if URL is a Git repository
-	err = exec.Command("git", "ls-remote", normalizedURLObject.String()).Run()
+	isRepository, err := gitHost.RepositoryExists(normalizedURLObject)
 	if err != nil {
-		if _, ok := err.(*exec.ExitError); ok {
-			submission.Error = "Submission URL is not a Git clone URL (e.g., `https://github.com/arduino-libraries/Servo`)."
-			return submission, "", true
-		}
-
-		panic(err)
+		return submission, "", true, newInternalError(GitLsRemoteError, "Unable to check whether %s is a Git repository: %s", normalizedURLObject.String(), err)
+	}
+	if !isRepository {
+		submission.Error = "Submission URL is not a Git clone URL (e.g., `https://github.com/arduino-libraries/Servo`)."
+		return submission, "", true, nil
 	}
 
-	submission.RepositoryName = strings.TrimSuffix(paths.New(normalizedURLObject.Path).Base(), ".git")
+	owner, repository, err := gitHost.OwnerAndRepository(normalizedURLObject)
+	if err != nil {
+		submission.Error = "Submission URL is not a Git clone URL (e.g., `https://github.com/arduino-libraries/Servo`)."
+		return submission, "", true, nil
+	}
+	submission.RepositoryName = repository
 
 	// Check if the URL is already in the index.
-	listLines, err := listPath.ReadFileAsLines()
 	for _, listURL := range listLines {
 		listURLObject, err := url.Parse(strings.TrimSpace(listURL))
 		if err != nil {
-			panic(err) // All list items have already passed parsing so something is broken if this happens.
+			// All list items have already passed parsing so something is broken if this happens.
+			return submission, "", true, newInternalError(ListParseError, "Unable to parse list item %s: %s", listURL, err)
 		}
 
-		normalizedListURLObject := normalizeURL(listURLObject)
+		listGitHost, _ := gitHostProviderForURL(listURLObject)
+		normalizedListURLObject := listGitHost.NormalizeURL(listURLObject)
 		if normalizedListURLObject.String() == normalizedURLObject.String() {
-			normalizedSubmissionURLObject := normalizeURL(submissionURLObject)
+			submissionGitHost, _ := gitHostProviderForURL(submissionURLObject)
+			normalizedSubmissionURLObject := submissionGitHost.NormalizeURL(submissionURLObject)
 			if normalizedURLObject.String() == normalizedSubmissionURLObject.String() {
 				submission.Error = "Submission URL is already in the Library Manager index."
 			} else {
 				submission.Error = fmt.Sprintf("Resolved URL %s is already in the Library Manager index.", normalizedURLObject.String())
 			}
-			return submission, "", true
+			return submission, "", true, nil
 		}
 	}
 
 	// Determine the library types attributes.
-	submission.Official = uRLIsUnder(normalizedURLObject, officialOrganizations)
 	var types []string
-	if submission.Official {
-		types = append(types, "Arduino")
-	}
-	if uRLIsUnder(normalizedURLObject, partnerOrganizations) {
-		types = append(types, "Partner")
-	}
-	if uRLIsUnder(normalizedURLObject, recommendedOrganizations) {
-		types = append(types, "Recommended")
-	}
-	if types == nil {
-		types = append(types, "Contributed")
-	}
+	types, submission.Official = libraryTypes(normalizedURLObject, typesConfiguration)
 
 	submissionClonePath, err := paths.MkTempDir("", "")
 	if err != nil {
-		panic(err)
+		return submission, "", true, newInternalError(CloneFolderError, "Unable to create folder for clone: %s", err)
 	}
+	defer submissionClonePath.RemoveAll()
 
-	err = exec.Command("git", "clone", "--depth", "1", normalizedURLObject.String(), submissionClonePath.String()).Run()
+	err = gitClient.Clone(normalizedURLObject.String(), submissionClonePath)
 	if err != nil {
-		panic(err)
+		return submission, "", true, newInternalError(GitCloneError, "Unable to clone %s: %s", normalizedURLObject.String(), err)
 	}
 
-	// Determine latest tag name in submission repo
-	err = os.Chdir(submissionClonePath.String())
+	// Determine the release tag name in submission repo
+	err = gitClient.FetchTags(submissionClonePath)
 	if err != nil {
-		panic(err)
+		return submission, "", true, newInternalError(GitFetchTagsError, "Unable to fetch tags of %s: %s", normalizedURLObject.String(), err)
 	}
-	err = exec.Command("git", "fetch", "--tags").Run()
+	tags, err := gitClient.Tags(submissionClonePath)
 	if err != nil {
-		panic(err)
+		return submission, "", true, newInternalError(GitTagError, "Unable to list tags of %s: %s", normalizedURLObject.String(), err)
 	}
-	tagList, err := exec.Command("git", "rev-list", "--tags", "--max-count=1").Output()
+	if len(tags) == 0 {
+		submission.Error = "The repository has no tags. You need to create a [release](https://docs.github.com/en/github/administering-a-repository/managing-releases-in-a-repository) or [tag](https://git-scm.com/docs/git-tag) that matches the `version` value in the library's library.properties file."
+		return submission, "", true, nil
+	}
+	latestTag, err := gitClient.LatestTag(submissionClonePath)
 	if err != nil {
-		panic(err)
+		return submission, "", true, newInternalError(GitTagError, "Unable to determine latest tag of %s: %s", normalizedURLObject.String(), err)
 	}
-	if string(tagList) == "" {
-		submission.Error = "The repository has no tags. You need to create a [release](https://docs.github.com/en/github/administering-a-repository/managing-releases-in-a-repository) or [tag](https://git-scm.com/docs/git-tag) that matches the `version` value in the library's library.properties file."
-		return submission, "", true
+	var releaseTags []string
+	if apiClient, ok := hostAPIClients[gitHost.Host()]; ok && options.preferReleases {
+		releaseTags, err = apiClient.ReleaseTags(owner, repository)
+		if err != nil {
+			return submission, "", true, newInternalError(HostAPIError, "Unable to list releases of %s: %s", normalizedURLObject.String(), err)
+		}
 	}
-	latestTag, err := exec.Command("git", "describe", "--tags", strings.TrimSpace(string(tagList))).Output()
+	var tagSelection tagSelectionType
+	submission.Tag, tagSelection = selectReleaseTag(tags, releaseTags, latestTag)
+	submission.TagSelection = &tagSelection
+
+	// Checkout release tag.
+	err = gitClient.Checkout(submissionClonePath, submission.Tag)
 	if err != nil {
-		panic(err)
+		return submission, "", true, newInternalError(GitCheckoutError, "Unable to check out tag %s of %s: %s", submission.Tag, normalizedURLObject.String(), err)
 	}
-	submission.Tag = strings.TrimSpace(string(latestTag))
 
-	// Checkout latest tag.
-	err = exec.Command("git", "checkout", submission.Tag).Run()
+	// Flag content that is unsafe or that won't work as expected in the release.
+	submission.ContentFindings, err = scanContent(submissionClonePath)
 	if err != nil {
-		panic(err)
+		return submission, "", true, newInternalError(ContentScanError, "Unable to scan content of tag %s of %s: %s", submission.Tag, normalizedURLObject.String(), err)
 	}
 
 	// Get submission library name. It is necessary to record this in the index source entry because the library is locked to this name.
 	libraryPropertiesPath := submissionClonePath.Join("library.properties")
 	if !libraryPropertiesPath.Exist() {
 		submission.Error = "Library is missing a library.properties metadata file.%0A%0ASee: https://arduino.github.io/arduino-cli/latest/library-specification/#library-metadata"
-		return submission, "", true
+		return submission, "", true, nil
 	}
 	libraryProperties, err := properties.LoadFromPath(libraryPropertiesPath)
 	if err != nil {
 		submission.Error = fmt.Sprintf("Invalid library.properties file: %s%%0A%%0ASee: https://arduino.github.io/arduino-cli/latest/library-specification/#library-metadata", err)
-		return submission, "", true
+		return submission, "", true, nil
 	}
+	submission.LibraryPropertiesFindings = validateLibraryProperties(libraryProperties)
 	var ok bool
 	submission.Name, ok = libraryProperties.GetOk("name")
 	if !ok {
 		submission.Error = "library.properties is missing a name field.%0A%0ASee: https://arduino.github.io/arduino-cli/latest/library-specification/#library-metadata"
-		return submission, "", true
+		return submission, "", true, nil
+	}
+
+	if submission.OldURL != "" {
+		flagNameChange(&submission, libraryIndex)
+	}
+
+	if options.namePolicy != nil && !exemptFromNamePolicy(submission, libraryIndex) {
+		if violations := checkLibraryName(submission.Name, submission.Official, types, *options.namePolicy); violations != nil {
+			submission.Error = strings.Join(violations, "%0A%0A")
+			return submission, "", true, nil
+		}
+	}
+
+	// Library names must be unique in Library Manager. The name of a library whose URL is being replaced is not in
+	// conflict.
+	if libraryIndex != nil {
+		if repository, found := libraryIndex.repositoryForName(submission.Name); found && repository != submission.NormalizedURL && !replacesURL(submission, repository) {
+			submission.Error = fmt.Sprintf("Library name `%s` is already in use by %s.%%0AThe name of each library in Library Manager must be unique. Please change the `name` value in library.properties.%%0A%%0ASee: https://arduino.github.io/arduino-cli/latest/library-specification/#libraryproperties-file-format", submission.Name, repository)
+			return submission, "", true, nil
+		}
+
+		// Names confusable with those of libraries in Library Manager could be used to impersonate them.
+		if options.namePolicy != nil && !exemptFromNamePolicy(submission, libraryIndex) {
+			if checkSimilarNames(&submission, libraryIndex, options.namePolicy.Similarity) {
+				return submission, "", true, nil
+			}
+		}
+	}
+
+	// Check the library version, since releases where it doesn't match the tag are rejected by the indexer.
+	version, ok := libraryProperties.GetOk("version")
+	if !ok {
+		submission.Error = "library.properties is missing a version field.%0A%0ASee: https://arduino.github.io/arduino-cli/latest/library-specification/#library-metadata"
+		return submission, "", true, nil
+	}
+	if _, err := parseVersion(version); err != nil {
+		submission.Error = fmt.Sprintf("library.properties `version` value `%s` is not compliant with the [semver](https://semver.org/) specification.%%0A%%0ASee: https://arduino.github.io/arduino-cli/latest/library-specification/#library-metadata", version)
+		return submission, "", true, nil
+	}
+	if mismatchError := versionTagMismatchError(version, submission.Tag); mismatchError != "" {
+		submission.Error = mismatchError
+		return submission, "", true, nil
+	}
+
+	// Check that the library's dependencies can be installed from Library Manager.
+	if depends, ok := libraryProperties.GetOk("depends"); ok && libraryIndex != nil && strings.TrimSpace(depends) != "" {
+		// Syntax problems are reported in the library.properties findings.
+		if dependencies, err := parseDepends(depends); err == nil {
+			submission.DependencyFindings = resolveDependencies(submission.Name, dependencies, libraryIndex)
+		}
+	}
+
+	// This must be the final use of the clone, since it checks out each tag.
+	if options.deep {
+		submission.Releases, err = checkReleases(submissionClonePath, tags, submission.Name)
+		if err != nil {
+			return submission, "", true, newInternalError(GitCheckoutError, "Unable to check releases of %s: %s", normalizedURLObject.String(), err)
+		}
+		var skippedTags []string
+		for _, releaseCheck := range submission.Releases {
+			if !releaseCheck.Indexable {
+				skippedTags = append(skippedTags, "`"+releaseCheck.Tag+"`")
+			}
+		}
+		if skippedTags != nil {
+			submission.Warnings = append(submission.Warnings, fmt.Sprintf("The Library Manager indexer will skip the releases of tags %s.", strings.Join(skippedTags, ", ")))
+		}
 	}
 
 	// Assemble Library Manager index source entry string
@@ -483,7 +934,29 @@ func populateSubmission(submissionURL string, listPath *paths.Path, accessList [
 		indexSourceSeparator,
 	)
 
-	return submission, indexEntry, true
+	return submission, indexEntry, true, nil
+}
+
+// commandErrorMessage returns a message for a failed command, including the command's output.
+func commandErrorMessage(err error, output []byte) string {
+	trimmedOutput := strings.TrimSpace(string(output))
+	if trimmedOutput == "" {
+		return err.Error()
+	}
+
+	return fmt.Sprintf("%s%%0A%s", err, strings.ReplaceAll(trimmedOutput, "\n", "%0A"))
+}
+
+// gitCommand returns the command to run git with the arguments in the repository folder, or in the process working
+// directory if repositoryPath is nil. The working directory is set per command rather than for the process so that
+// submissions can be processed concurrently.
+func gitCommand(repositoryPath *paths.Path, arguments ...string) *exec.Cmd {
+	command := exec.Command("git", arguments...)
+	if repositoryPath != nil {
+		command.Dir = repositoryPath.String()
+	}
+
+	return command
 }
 
 // normalizeURL converts the URL into the standardized format used in the index.
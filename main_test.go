@@ -22,9 +22,17 @@
 package main
 
 import (
+	"errors"
+	"io"
+	"net/http"
 	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/arduino/go-paths-helper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -46,11 +54,13 @@ index cff484d..e14c179 100644
 +https://github.com/foo/bar
 `)
 
-	requestType, requestError, arduinoLintLibraryManagerSetting, submissionURLs := parseDiff(diff, "repositories.txt")
+	requestType, requestError, arduinoLintLibraryManagerSetting, submissions, removals, err := parseDiff(diff, "repositories.txt")
 	assert.Equal(t, "other", requestType, testName)
+	assert.Nil(t, err, testName)
 	assert.Equal(t, "", requestError, testName)
 	assert.Equal(t, "", arduinoLintLibraryManagerSetting, testName)
-	assert.Nil(t, submissionURLs, testName)
+	assert.Nil(t, submissions, testName)
+	assert.Nil(t, removals, testName)
 
 	testName = "Not list"
 	diff = []byte(`
@@ -63,11 +73,13 @@ index d4edde0..807b76d 100644
 +hello
 `)
 
-	requestType, requestError, arduinoLintLibraryManagerSetting, submissionURLs = parseDiff(diff, "repositories.txt")
+	requestType, requestError, arduinoLintLibraryManagerSetting, submissions, removals, err = parseDiff(diff, "repositories.txt")
 	assert.Equal(t, "other", requestType, testName)
+	assert.Nil(t, err, testName)
 	assert.Equal(t, "", requestError, testName)
 	assert.Equal(t, "", arduinoLintLibraryManagerSetting, testName)
-	assert.Nil(t, submissionURLs, testName)
+	assert.Nil(t, submissions, testName)
+	assert.Nil(t, removals, testName)
 
 	testName = "List filename change"
 	diff = []byte(`
@@ -82,11 +94,13 @@ index cff484d..e14c179 100644
 +https://github.com/foo/bar
 `)
 
-	requestType, requestError, arduinoLintLibraryManagerSetting, submissionURLs = parseDiff(diff, "repositories.txt")
+	requestType, requestError, arduinoLintLibraryManagerSetting, submissions, removals, err = parseDiff(diff, "repositories.txt")
 	assert.Equal(t, "other", requestType, testName)
+	assert.Nil(t, err, testName)
 	assert.Equal(t, "", requestError, testName)
 	assert.Equal(t, "", arduinoLintLibraryManagerSetting, testName)
-	assert.Nil(t, submissionURLs, testName)
+	assert.Nil(t, submissions, testName)
+	assert.Nil(t, removals, testName)
 
 	testName = "Submission"
 	diff = []byte(`
@@ -99,11 +113,13 @@ index cff484d..9f67763 100644
 +https://github.com/foo/baz
 `)
 
-	requestType, requestError, arduinoLintLibraryManagerSetting, submissionURLs = parseDiff(diff, "repositories.txt")
+	requestType, requestError, arduinoLintLibraryManagerSetting, submissions, removals, err = parseDiff(diff, "repositories.txt")
 	assert.Equal(t, "submission", requestType, testName)
+	assert.Nil(t, err, testName)
 	assert.Equal(t, "", requestError, testName)
 	assert.Equal(t, "submit", arduinoLintLibraryManagerSetting, testName)
-	assert.ElementsMatch(t, []string{"https://github.com/foo/bar", "https://github.com/foo/baz"}, submissionURLs, testName)
+	assert.Equal(t, []listChangeType{{URL: "https://github.com/foo/bar", File: "repositories.txt", Line: 9}, {URL: "https://github.com/foo/baz", File: "repositories.txt", Line: 10}}, submissions, testName)
+	assert.Nil(t, removals, testName)
 
 	testName = "Submission w/ no newline at end of file"
 	diff = []byte(`
@@ -116,11 +132,13 @@ index cff484d..1b0b80b 100644
 \ No newline at end of file
 `)
 
-	requestType, requestError, arduinoLintLibraryManagerSetting, submissionURLs = parseDiff(diff, "repositories.txt")
+	requestType, requestError, arduinoLintLibraryManagerSetting, submissions, removals, err = parseDiff(diff, "repositories.txt")
 	assert.Equal(t, "invalid", requestType, testName)
+	assert.Nil(t, err, testName)
 	assert.Equal(t, "Pull request removes newline from the end of a file.%0APlease add a blank line to the end of the file.", requestError, testName)
 	assert.Equal(t, "", arduinoLintLibraryManagerSetting, testName)
-	assert.Nil(t, submissionURLs, testName)
+	assert.Nil(t, submissions, testName)
+	assert.Nil(t, removals, testName)
 
 	testName = "Submission w/ blank line"
 	diff = []byte(`
@@ -133,11 +151,13 @@ index cff484d..1b0b80b 100644
 +
 `)
 
-	requestType, requestError, arduinoLintLibraryManagerSetting, submissionURLs = parseDiff(diff, "repositories.txt")
+	requestType, requestError, arduinoLintLibraryManagerSetting, submissions, removals, err = parseDiff(diff, "repositories.txt")
 	assert.Equal(t, "submission", requestType, testName)
+	assert.Nil(t, err, testName)
 	assert.Equal(t, "", requestError, testName)
 	assert.Equal(t, "submit", arduinoLintLibraryManagerSetting, testName)
-	assert.ElementsMatch(t, []string{"https://github.com/foo/bar"}, submissionURLs, testName)
+	assert.Equal(t, []listChangeType{{URL: "https://github.com/foo/bar", File: "repositories.txt", Line: 3392}}, submissions, testName)
+	assert.Nil(t, removals, testName)
 
 	testName = "Removal"
 	diff = []byte(`
@@ -149,11 +169,13 @@ index cff484d..38e11d8 100644
 -https://github.com/arduino-libraries/Ethernet
 `)
 
-	requestType, requestError, arduinoLintLibraryManagerSetting, submissionURLs = parseDiff(diff, "repositories.txt")
+	requestType, requestError, arduinoLintLibraryManagerSetting, submissions, removals, err = parseDiff(diff, "repositories.txt")
 	assert.Equal(t, "removal", requestType, testName)
+	assert.Nil(t, err, testName)
 	assert.Equal(t, "", requestError, testName)
 	assert.Equal(t, "", arduinoLintLibraryManagerSetting, testName)
-	assert.Nil(t, submissionURLs, testName)
+	assert.Nil(t, submissions, testName)
+	assert.Equal(t, []listChangeType{{URL: "https://github.com/arduino-libraries/Ethernet", File: "repositories.txt", Line: 8}}, removals, testName)
 
 	testName = "Modification"
 	diff = []byte(`
@@ -166,11 +188,13 @@ index cff484d..8b401a1 100644
 +https://github.com/foo/bar
 `)
 
-	requestType, requestError, arduinoLintLibraryManagerSetting, submissionURLs = parseDiff(diff, "repositories.txt")
+	requestType, requestError, arduinoLintLibraryManagerSetting, submissions, removals, err = parseDiff(diff, "repositories.txt")
 	assert.Equal(t, "modification", requestType, testName)
+	assert.Nil(t, err, testName)
 	assert.Equal(t, "", requestError, testName)
 	assert.Equal(t, "update", arduinoLintLibraryManagerSetting, testName)
-	assert.Equal(t, []string{"https://github.com/foo/bar"}, submissionURLs, testName)
+	assert.Equal(t, []listChangeType{{URL: "https://github.com/foo/bar", ReplacedURL: "https://github.com/arduino-libraries/Ethernet", File: "repositories.txt", Line: 8}}, submissions, testName)
+	assert.Equal(t, []listChangeType{{URL: "https://github.com/arduino-libraries/Ethernet", File: "repositories.txt", Line: 8}}, removals, testName)
 
 	testName = "Newline-only"
 	diff = []byte(`
@@ -186,11 +210,216 @@ index d9a6136..ca902d9 100644
 +
 `)
 
-	requestType, requestError, arduinoLintLibraryManagerSetting, submissionURLs = parseDiff(diff, "repositories.txt")
+	requestType, requestError, arduinoLintLibraryManagerSetting, submissions, removals, err = parseDiff(diff, "repositories.txt")
 	assert.Equal(t, "other", requestType, testName)
+	assert.Nil(t, err, testName)
 	assert.Equal(t, "", requestError, testName)
 	assert.Equal(t, "", arduinoLintLibraryManagerSetting, testName)
-	assert.Nil(t, submissionURLs, testName)
+	assert.Nil(t, submissions, testName)
+	assert.Nil(t, removals, testName)
+}
+
+func Test_parseDiffModificationPairing(t *testing.T) {
+	diff := []byte(`
+diff --git a/repositories.txt b/repositories.txt
+index cff484d..8b401a1 100644
+--- a/repositories.txt
++++ b/repositories.txt
+@@ -2,2 +2,2 @@ https://github.com/firmata/arduino
+-https://github.com/foo/Servo
+-https://github.com/foo/Stepper
++https://github.com/bar/stepper
++https://github.com/bar/baz
+@@ -10 +10,0 @@ https://github.com/arduino-libraries/Ethernet
+-https://github.com/foo/qux
+@@ -20,0 +20 @@ https://github.com/arduino-libraries/Ethernet
++https://github.com/bar/qux
+`)
+
+	requestType, _, _, submissions, removals, err := parseDiff(diff, "repositories.txt")
+	require.Nil(t, err)
+	assert.Equal(t, "modification", requestType)
+	assert.Equal(
+		t,
+		[]listChangeType{
+			{URL: "https://github.com/foo/Servo", File: "repositories.txt", Line: 2},
+			{URL: "https://github.com/foo/Stepper", File: "repositories.txt", Line: 3},
+			{URL: "https://github.com/foo/qux", File: "repositories.txt", Line: 10},
+		},
+		removals,
+	)
+	assert.Equal(
+		t,
+		[]listChangeType{
+			{URL: "https://github.com/bar/stepper", ReplacedURL: "https://github.com/foo/Stepper", File: "repositories.txt", Line: 2}, // Repository name in hunk.
+			{URL: "https://github.com/bar/baz", ReplacedURL: "https://github.com/foo/Servo", File: "repositories.txt", Line: 3},       // Position in hunk.
+			{URL: "https://github.com/bar/qux", ReplacedURL: "https://github.com/foo/qux", File: "repositories.txt", Line: 20},        // Repository name across hunks.
+		},
+		submissions,
+	)
+}
+
+func Test_parseDiffInternalError(t *testing.T) {
+	diff := []byte(`
+diff --git a/repositories.txt b/repositories.txt
+index cff484d..9f67763 100644
+--- a/repositories.txt
++++ b/repositories.txt
+@@ -8,0 +9,2 @@ https://github.com/arduino-libraries/Ethernet
++https://github.com/foo/bar
+-https://github.com/foo/baz
+@@ -foo
+`)
+
+	_, _, _, _, _, err := parseDiff(diff, "repositories.txt")
+	var parseError *internalError
+	require.ErrorAs(t, err, &parseError)
+	assert.Equal(t, DiffParseError, parseError.Code)
+}
+
+func Test_commandErrorMessage(t *testing.T) {
+	err := errors.New("exit status 128")
+	assert.Equal(t, "exit status 128", commandErrorMessage(err, []byte("\n")))
+	assert.Equal(t, "exit status 128%0Afatal: foo%0Abar", commandErrorMessage(err, []byte("fatal: foo\nbar\n")))
+}
+
+func Test_processSubmissions(t *testing.T) {
+	submissionURLs := []string{"https://github.com/foo/a", "https://github.com/foo/b", "https://github.com/foo/c", "https://github.com/foo/d", "https://github.com/foo/e"}
+	var submissions []listChangeType
+	for _, submissionURL := range submissionURLs {
+		submissions = append(submissions, listChangeType{URL: submissionURL})
+	}
+
+	for _, jobs := range []int{1, 2, 10} {
+		var activeJobs int32
+		var maxActiveJobs int32
+		submissionResults := processSubmissions(submissions, jobs, func(listChange listChangeType) (submissionType, string, bool, error) {
+			submissionURL := listChange.URL
+			active := atomic.AddInt32(&activeJobs, 1)
+			for {
+				maxActive := atomic.LoadInt32(&maxActiveJobs)
+				if active <= maxActive || atomic.CompareAndSwapInt32(&maxActiveJobs, maxActive, active) {
+					break
+				}
+			}
+			// Make later submissions finish first.
+			time.Sleep(time.Duration(len(submissionURLs)-strings.Index("abcde", submissionURL[len(submissionURL)-1:])) * time.Millisecond)
+			atomic.AddInt32(&activeJobs, -1)
+			if submissionURL == "https://github.com/foo/e" {
+				return submissionType{SubmissionURL: submissionURL}, "", true, newInternalError(GitCloneError, "foo")
+			}
+			return submissionType{SubmissionURL: submissionURL}, submissionURL + "|Contributed|foo", submissionURL != "https://github.com/foo/c", nil
+		})
+
+		require.Len(t, submissionResults, len(submissionURLs))
+		for submissionIndex, submissionResult := range submissionResults {
+			assert.Equal(t, submissionURLs[submissionIndex], submissionResult.submission.SubmissionURL, "Results are in submission order")
+			if submissionIndex == len(submissionURLs)-1 {
+				assert.Equal(t, newInternalError(GitCloneError, "foo"), submissionResult.err)
+				continue
+			}
+			assert.Nil(t, submissionResult.err)
+			assert.Equal(t, submissionURLs[submissionIndex]+"|Contributed|foo", submissionResult.indexEntry)
+			assert.Equal(t, submissionURLs[submissionIndex] != "https://github.com/foo/c", submissionResult.allowed)
+		}
+		assert.LessOrEqual(t, int(maxActiveJobs), jobs, "Concurrency is limited to the number of jobs")
+	}
+
+	assert.Empty(t, processSubmissions(nil, 4, nil))
+}
+
+// withTestRepository sets up the fixtures for tests of populateSubmission on https://github.com/foo/bar, with a fake Git
+// client serving the given tags. The tags map each tag name to the files of the repository at the tag.
+func withTestRepository(t *testing.T, tags map[string]map[string]string) {
+	withFixtures(t, createFixtures(t))
+	gitClient = &fakeGitClient{tags: tags}
+}
+
+// populateTestSubmission runs populateSubmission on a change to the list, with the default submitter access and a single
+// Contributed library type.
+func populateTestSubmission(listChange listChangeType, options submissionOptionsType) (submissionType, string, error) {
+	submission, indexEntry, _, err := populateSubmission(listChange, nil, []libraryTypeDataType{{Name: "Contributed", Default: true}}, nil, Default, nil, options)
+	return submission, indexEntry, err
+}
+
+// bodyTrackingTransport is an http.RoundTripper for use in tests that counts the requests and the response bodies that
+// are not closed.
+type bodyTrackingTransport struct {
+	transport  http.RoundTripper
+	requests   int32
+	openBodies int32
+}
+
+// trackedBody is a response body that updates the open body count of its transport when closed.
+type trackedBody struct {
+	io.ReadCloser
+	transport *bodyTrackingTransport
+	closed    bool
+}
+
+func (transport *bodyTrackingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := transport.transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	atomic.AddInt32(&transport.requests, 1)
+	atomic.AddInt32(&transport.openBodies, 1)
+	response.Body = &trackedBody{ReadCloser: response.Body, transport: transport}
+
+	return response, nil
+}
+
+func (body *trackedBody) Close() error {
+	if !body.closed {
+		body.closed = true
+		atomic.AddInt32(&body.transport.openBodies, -1)
+	}
+
+	return body.ReadCloser.Close()
+}
+
+func Test_populateSubmissionClosesResponses(t *testing.T) {
+	withTestRepository(t, map[string]map[string]string{"1.0.0": {"library.properties": "name=Foo\nversion=1.0.0\n"}})
+	transport := &bodyTrackingTransport{transport: httpClient.Transport}
+	httpClient.Transport = transport
+
+	_, _, err := populateTestSubmission(listChangeType{URL: "https://github.com/foo/bar"}, submissionOptionsType{})
+	require.Nil(t, err)
+	assert.NotZero(t, atomic.LoadInt32(&transport.requests), "Requests are made through the transport")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&transport.openBodies), "Response bodies are closed")
+}
+
+func Test_gitCommand(t *testing.T) {
+	workingDirectory, err := os.Getwd()
+	require.Nil(t, err)
+	repositoryPath, err := paths.MkTempDir("", "")
+	require.Nil(t, err)
+	defer repositoryPath.RemoveAll()
+
+	require.Nil(t, gitCommand(repositoryPath, "init").Run())
+	assert.True(t, repositoryPath.Join(".git").IsDir(), "Command is run in the repository folder")
+	currentWorkingDirectory, err := os.Getwd()
+	require.Nil(t, err)
+	assert.Equal(t, workingDirectory, currentWorkingDirectory, "Process working directory is not changed")
+}
+
+func Test_readDiff(t *testing.T) {
+	rawDiff, err := readDiff("-", strings.NewReader("foo"))
+	require.Nil(t, err)
+	assert.Equal(t, []byte("foo"), rawDiff, "Diff is read from stdin")
+
+	diffFolder, err := paths.MkTempDir("", "")
+	require.Nil(t, err)
+	defer diffFolder.RemoveAll()
+	diffPath := diffFolder.Join("diff.txt")
+	require.Nil(t, diffPath.WriteFile([]byte("bar")))
+
+	rawDiff, err = readDiff(diffPath.String(), strings.NewReader("foo"))
+	require.Nil(t, err)
+	assert.Equal(t, []byte("bar"), rawDiff, "Diff is read from file")
+
+	_, err = readDiff(diffFolder.Join("nonexistent.txt").String(), strings.NewReader("foo"))
+	assert.NotNil(t, err)
 }
 
 func Test_normalizeURL(t *testing.T) {
@@ -0,0 +1,125 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Flags for modifications that are sensitive according to the registry policy, and so need special review.
+const (
+	// OwnerChangeFlag means the replacement URL is under a different account than the replaced URL.
+	OwnerChangeFlag = "owner-change"
+	// NameChangeFlag means the library at the replacement URL has a different name than the one in the index.
+	NameChangeFlag = "name-change"
+)
+
+// normalizeReplacedURL returns the replaced URL in the standardized format used in the index.
+func normalizeReplacedURL(replacedURL string) (url.URL, error) {
+	replacedURLObject, err := url.Parse(replacedURL)
+	if err != nil {
+		return url.URL{}, err
+	}
+	gitHost, _ := gitHostProviderForURL(replacedURLObject)
+
+	return gitHost.NormalizeURL(replacedURLObject), nil
+}
+
+// replacesURL returns whether the submission replaces the normalized URL in the list.
+func replacesURL(submission submissionType, normalizedURL string) bool {
+	if submission.OldURL == "" {
+		return false
+	}
+	normalizedReplacedURL, err := normalizeReplacedURL(submission.OldURL)
+
+	return err == nil && normalizedReplacedURL.String() == normalizedURL
+}
+
+// flagOwnerChange flags the submission if the owner of the library repository differs from that of the replaced URL.
+func flagOwnerChange(submission *submissionType, normalizedURL url.URL) {
+	normalizedReplacedURL, err := normalizeReplacedURL(submission.OldURL)
+	if err != nil {
+		return
+	}
+
+	owner := repositoryOwnerSlug(normalizedURL)
+	replacedOwner := repositoryOwnerSlug(normalizedReplacedURL)
+	// Account names are case-insensitive on the supported Git hosts.
+	if owner != "" && replacedOwner != "" && !strings.EqualFold(owner, replacedOwner) {
+		submission.ModificationFlags = append(submission.ModificationFlags, OwnerChangeFlag)
+		submission.Warnings = append(submission.Warnings, fmt.Sprintf("Modification changes the library repository owner from `%s` to `%s`.", replacedOwner, owner))
+	}
+}
+
+// flagNameChange flags the submission if the library name differs from the name recorded in the index for the replaced
+// URL.
+func flagNameChange(submission *submissionType, libraryIndex *libraryIndexType) {
+	if libraryIndex == nil {
+		return
+	}
+	normalizedReplacedURL, err := normalizeReplacedURL(submission.OldURL)
+	if err != nil {
+		return
+	}
+
+	replacedName, found := libraryIndex.nameForRepository(normalizedReplacedURL.String())
+	if found && replacedName != submission.Name {
+		submission.ModificationFlags = append(submission.ModificationFlags, NameChangeFlag)
+		submission.Warnings = append(submission.Warnings, fmt.Sprintf("Modification changes the library name from `%s` to `%s`.", replacedName, submission.Name))
+	}
+}
+
+// keepsName returns whether the submission is a modification that keeps the library name of the replaced URL in the
+// index.
+func keepsName(submission submissionType, libraryIndex *libraryIndexType) bool {
+	if libraryIndex == nil || submission.OldURL == "" {
+		return false
+	}
+	normalizedReplacedURL, err := normalizeReplacedURL(submission.OldURL)
+	if err != nil {
+		return false
+	}
+
+	replacedName, found := libraryIndex.nameForRepository(normalizedReplacedURL.String())
+	return found && replacedName == submission.Name
+}
+
+// exemptFromNamePolicy returns whether the submission's library name is exempt from the name policy. Names already in
+// Library Manager are exempt, since changing them would break existing users. Without the index, the name can't be
+// shown to be unchanged, so it is not exempt.
+func exemptFromNamePolicy(submission submissionType, libraryIndex *libraryIndexType) bool {
+	return keepsName(submission, libraryIndex)
+}
+
+// repositoryOwnerSlug returns the `<host>/<owner>` slug of the account that owns the repository at the normalized URL,
+// or an empty string if it can't be determined.
+func repositoryOwnerSlug(normalizedURL url.URL) string {
+	gitHost, _ := gitHostProviderForURL(&normalizedURL)
+	owner, _, err := gitHost.OwnerAndRepository(normalizedURL)
+	if err != nil {
+		return ""
+	}
+
+	return gitHost.Host() + "/" + owner
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_replacesURL(t *testing.T) {
+	assert.True(t, replacesURL(submissionType{OldURL: "https://github.com/foo/bar"}, "https://github.com/foo/bar.git"))
+	assert.False(t, replacesURL(submissionType{OldURL: "https://github.com/foo/bar"}, "https://github.com/foo/baz.git"))
+	assert.False(t, replacesURL(submissionType{}, "https://github.com/foo/bar.git"))
+}
+
+func Test_flagOwnerChange(t *testing.T) {
+	testTables := []struct {
+		testName                  string
+		oldURL                    string
+		normalizedURL             string
+		expectedModificationFlags []string
+		expectedWarnings          []string
+	}{
+		{"Same owner", "https://github.com/foo/bar", "https://github.com/foo/baz.git", nil, nil},
+		{"Owner case change", "https://github.com/Foo/bar", "https://github.com/foo/bar.git", nil, nil},
+		{"Owner change", "https://github.com/foo/bar", "https://github.com/qux/bar.git", []string{OwnerChangeFlag}, []string{"Modification changes the library repository owner from `github.com/foo` to `github.com/qux`."}},
+		{"Host change", "https://github.com/foo/bar", "https://gitlab.com/foo/bar.git", []string{OwnerChangeFlag}, []string{"Modification changes the library repository owner from `github.com/foo` to `gitlab.com/foo`."}},
+	}
+
+	for _, testTable := range testTables {
+		normalizedURL, err := url.Parse(testTable.normalizedURL)
+		require.Nil(t, err)
+
+		submission := submissionType{OldURL: testTable.oldURL}
+		flagOwnerChange(&submission, *normalizedURL)
+		assert.Equal(t, testTable.expectedModificationFlags, submission.ModificationFlags, testTable.testName)
+		assert.Equal(t, testTable.expectedWarnings, submission.Warnings, testTable.testName)
+	}
+}
+
+func Test_flagNameChange(t *testing.T) {
+	libraryIndex, err := parseLibraryIndex([]byte("https://github.com/foo/bar.git|Contributed|Foo Bar\n"))
+	require.Nil(t, err)
+
+	submission := submissionType{OldURL: "https://github.com/foo/bar", Name: "Foo Bar"}
+	flagNameChange(&submission, libraryIndex)
+	assert.Nil(t, submission.ModificationFlags, "Same name")
+
+	submission = submissionType{OldURL: "https://github.com/foo/bar", Name: "Baz"}
+	flagNameChange(&submission, libraryIndex)
+	assert.Equal(t, []string{NameChangeFlag}, submission.ModificationFlags, "Name change")
+	assert.Equal(t, []string{"Modification changes the library name from `Foo Bar` to `Baz`."}, submission.Warnings, "Name change")
+
+	submission = submissionType{OldURL: "https://github.com/foo/baz", Name: "Baz"}
+	flagNameChange(&submission, libraryIndex)
+	assert.Nil(t, submission.ModificationFlags, "Replaced URL not in index")
+
+	submission = submissionType{OldURL: "https://github.com/foo/bar", Name: "Baz"}
+	flagNameChange(&submission, nil)
+	assert.Nil(t, submission.ModificationFlags, "No index")
+}
+
+func Test_keepsName(t *testing.T) {
+	libraryIndex, err := parseLibraryIndex([]byte("https://github.com/foo/bar.git|Contributed|Foo Bar\n"))
+	require.Nil(t, err)
+
+	assert.True(t, keepsName(submissionType{OldURL: "https://github.com/foo/bar", Name: "Foo Bar"}, libraryIndex), "Same name")
+	assert.False(t, keepsName(submissionType{OldURL: "https://github.com/foo/bar", Name: "Baz"}, libraryIndex), "Name change")
+	assert.False(t, keepsName(submissionType{OldURL: "https://github.com/foo/baz", Name: "Foo Bar"}, libraryIndex), "Replaced URL not in index")
+	assert.False(t, keepsName(submissionType{Name: "Foo Bar"}, libraryIndex), "Not a modification")
+	assert.False(t, keepsName(submissionType{OldURL: "https://github.com/foo/bar", Name: "Foo Bar"}, nil), "No index")
+}
+
+func Test_exemptFromNamePolicy(t *testing.T) {
+	libraryIndex, err := parseLibraryIndex([]byte("https://github.com/foo/bar.git|Contributed|Foo Bar\n"))
+	require.Nil(t, err)
+
+	assert.True(t, exemptFromNamePolicy(submissionType{OldURL: "https://github.com/foo/bar", Name: "Foo Bar"}, libraryIndex), "Same name")
+	assert.False(t, exemptFromNamePolicy(submissionType{OldURL: "https://github.com/foo/bar", Name: "Baz"}, libraryIndex), "Name change")
+	assert.False(t, exemptFromNamePolicy(submissionType{Name: "Foo Bar"}, libraryIndex), "Not a modification")
+	assert.False(t, exemptFromNamePolicy(submissionType{OldURL: "https://github.com/foo/bar", Name: "Foo Bar"}, nil), "Modification without index")
+	assert.False(t, exemptFromNamePolicy(submissionType{Name: "Foo Bar"}, nil), "Not a modification without index")
+}
@@ -0,0 +1,175 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// namePolicyType is the type of the library name policy configuration data.
+type namePolicyType struct {
+	MaxLength   int                  `yaml:"maxlength"`   // Maximum number of characters in the name. 0 means no limit.
+	Punctuation string               `yaml:"punctuation"` // Characters allowed in the name in addition to basic letters and numbers.
+	Reference   string               `yaml:"reference"`   // URL of the documentation of the length and character rules.
+	Reserved    []reservedNamesType  `yaml:"reserved"`    // Names and prefixes not available to all libraries.
+	Similarity  similarityPolicyType `yaml:"similarity"`  // Thresholds for names similar to those of libraries in Library Manager.
+}
+
+// similarityPolicyType is the type of the data for the thresholds of the edit distance between the skeletons of the
+// library name and the names of libraries in Library Manager. -1 disables a threshold.
+type similarityPolicyType struct {
+	ErrorDistance   int    `yaml:"errordistance"`   // Maximum distance between the confusable skeletons for which the submission is rejected.
+	WarningDistance int    `yaml:"warningdistance"` // Maximum distance for which the submission is flagged for review.
+	Reference       string `yaml:"reference"`       // URL of the documentation of the name uniqueness requirement.
+}
+
+// reservedNamesType is the type of the data for a group of reserved library names.
+type reservedNamesType struct {
+	Names     []string `yaml:"names"`     // Reserved names. Matched case-insensitively.
+	Prefixes  []string `yaml:"prefixes"`  // Reserved name prefixes. Matched case-insensitively.
+	Official  bool     `yaml:"official"`  // Whether official libraries may use the names.
+	Types     []string `yaml:"types"`     // Names of the library types that may use the names.
+	Reason    string   `yaml:"reason"`    // Why the names are reserved, completing the sentence "The name is reserved ...".
+	Reference string   `yaml:"reference"` // URL of the documentation of the reservation.
+}
+
+// defaultNamePolicy is the library name policy used when no name policy file is provided.
+var defaultNamePolicy = namePolicyType{
+	MaxLength:   63,
+	Punctuation: " _.-",
+	Reference:   "https://arduino.github.io/arduino-cli/latest/library-specification/#libraryproperties-file-format",
+	Reserved: []reservedNamesType{
+		{
+			Prefixes:  []string{"Arduino"},
+			Official:  true,
+			Reason:    "for official Arduino libraries",
+			Reference: "https://github.com/arduino/library-registry/blob/main/FAQ.md#what-are-the-requirements-for-a-library-to-be-added-to-library-manager",
+		},
+		{
+			Names:     []string{"EEPROM", "HID", "SoftwareSerial", "SPI", "Wire"},
+			Official:  true,
+			Reason:    "because it is used by a library bundled with Arduino boards platforms",
+			Reference: "https://github.com/arduino/library-registry/blob/main/FAQ.md#what-are-the-requirements-for-a-library-to-be-added-to-library-manager",
+		},
+	},
+	Similarity: similarityPolicyType{
+		ErrorDistance:   0,
+		WarningDistance: 1,
+		Reference:       "https://arduino.github.io/arduino-cli/latest/library-specification/#libraryproperties-file-format",
+	},
+}
+
+// parseNamePolicy parses and validates the library name policy file data. Keys not in the data keep their default
+// values.
+func parseNamePolicy(rawNamePolicy []byte) (namePolicyType, error) {
+	namePolicy := defaultNamePolicy
+	decoder := yaml.NewDecoder(bytes.NewReader(rawNamePolicy))
+	decoder.KnownFields(true)
+	err := decoder.Decode(&namePolicy)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return namePolicyType{}, err
+	}
+
+	if namePolicy.MaxLength < 0 {
+		return namePolicyType{}, fmt.Errorf("maxlength must not be negative")
+	}
+	if namePolicy.Reference == "" {
+		return namePolicyType{}, fmt.Errorf("reference is required")
+	}
+	if namePolicy.Similarity.ErrorDistance < -1 || namePolicy.Similarity.WarningDistance < -1 {
+		return namePolicyType{}, fmt.Errorf("similarity distances must be -1 (disabled) or greater")
+	}
+	if namePolicy.Similarity.Reference == "" {
+		return namePolicyType{}, fmt.Errorf("similarity reference is required")
+	}
+	for reservedIndex, reserved := range namePolicy.Reserved {
+		if len(reserved.Names) == 0 && len(reserved.Prefixes) == 0 {
+			return namePolicyType{}, fmt.Errorf("Reserved entry #%d has no names or prefixes", reservedIndex+1)
+		}
+		if reserved.Reason == "" {
+			return namePolicyType{}, fmt.Errorf("Reserved entry #%d has no reason", reservedIndex+1)
+		}
+		if reserved.Reference == "" {
+			return namePolicyType{}, fmt.Errorf("Reserved entry #%d has no reference", reservedIndex+1)
+		}
+	}
+
+	return namePolicy, nil
+}
+
+// checkLibraryName returns the messages for the name policy rules violated by the library name. official and types are
+// the library's classification from the types configuration.
+func checkLibraryName(name string, official bool, types []string, namePolicy namePolicyType) []string {
+	var violations []string
+	addViolation := func(reference string, format string, a ...interface{}) {
+		violations = append(violations, fmt.Sprintf(format, a...)+"%0A%0ASee: "+reference)
+	}
+
+	if length := utf8.RuneCountInString(name); namePolicy.MaxLength > 0 && length > namePolicy.MaxLength {
+		addViolation(namePolicy.Reference, "Library name `%s` is %d characters long. The maximum length is %d characters.", name, length, namePolicy.MaxLength)
+	}
+
+	isBasicLetterOrNumber := func(character rune) bool {
+		return (character >= 'a' && character <= 'z') || (character >= 'A' && character <= 'Z') || (character >= '0' && character <= '9')
+	}
+	var invalidCharacters []string
+	for _, character := range name {
+		if !isBasicLetterOrNumber(character) && !strings.ContainsRune(namePolicy.Punctuation, character) && !slices.Contains(invalidCharacters, string(character)) {
+			invalidCharacters = append(invalidCharacters, string(character))
+		}
+	}
+	if invalidCharacters != nil {
+		addViolation(namePolicy.Reference, "Library name `%s` contains characters that are not allowed: `%s`. Names may only contain basic letters (A-Z or a-z), numbers (0-9), and `%s`.", name, strings.Join(invalidCharacters, "`, `"), namePolicy.Punctuation)
+	}
+	if firstCharacter, _ := utf8.DecodeRuneInString(name); !isBasicLetterOrNumber(firstCharacter) {
+		addViolation(namePolicy.Reference, "Library name `%s` must start with a basic letter (A-Z or a-z) or number (0-9).", name)
+	}
+	if strings.HasSuffix(name, " ") {
+		addViolation(namePolicy.Reference, "Library name `%s` must not end with a space.", name)
+	}
+
+	for _, reserved := range namePolicy.Reserved {
+		if (official && reserved.Official) || slices.ContainsFunc(types, func(libraryType string) bool { return slices.Contains(reserved.Types, libraryType) }) {
+			continue
+		}
+		for _, reservedName := range reserved.Names {
+			if strings.EqualFold(name, reservedName) {
+				addViolation(reserved.Reference, "Library name `%s` is reserved %s. Please change the `name` value in library.properties.", name, reserved.Reason)
+			}
+		}
+		for _, prefix := range reserved.Prefixes {
+			if len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+				addViolation(reserved.Reference, "Library names starting with `%s` are reserved %s. Please change the `name` value in library.properties.", prefix, reserved.Reason)
+			}
+		}
+	}
+
+	return violations
+}
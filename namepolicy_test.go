@@ -0,0 +1,147 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseNamePolicy(t *testing.T) {
+	namePolicy, err := parseNamePolicy([]byte(""))
+	require.Nil(t, err)
+	assert.Equal(t, defaultNamePolicy, namePolicy, "Empty file uses the default policy")
+
+	namePolicy, err = parseNamePolicy([]byte(`
+maxlength: 20
+reserved:
+  - prefixes:
+      - Foo
+    types:
+      - Partner
+    reason: for partner libraries
+    reference: https://example.com
+`))
+	require.Nil(t, err)
+	assert.Equal(t, 20, namePolicy.MaxLength)
+	assert.Equal(t, defaultNamePolicy.Punctuation, namePolicy.Punctuation, "Omitted keys keep the default value")
+	assert.Equal(t, []reservedNamesType{{Prefixes: []string{"Foo"}, Types: []string{"Partner"}, Reason: "for partner libraries", Reference: "https://example.com"}}, namePolicy.Reserved)
+
+	testTables := []struct {
+		testName       string
+		rawNamePolicy  string
+		errorAssertion assert.ValueAssertionFunc
+	}{
+		{"Unknown key", "maxlenght: 20", assert.NotNil},
+		{"Negative length", "maxlength: -1", assert.NotNil},
+		{"No reference", "reference: ''", assert.NotNil},
+		{"Reserved without names", "reserved: [{reason: foo, reference: https://example.com}]", assert.NotNil},
+		{"Reserved without reason", "reserved: [{names: [Foo], reference: https://example.com}]", assert.NotNil},
+		{"Reserved without reference", "reserved: [{names: [Foo], reason: foo}]", assert.NotNil},
+		{"Invalid similarity distance", "similarity: {errordistance: -2}", assert.NotNil},
+		{"No similarity reference", "similarity: {reference: ''}", assert.NotNil},
+		{"Similarity disabled", "similarity: {errordistance: -1, warningdistance: -1}", assert.Nil},
+		{"No length limit", "maxlength: 0", assert.Nil},
+	}
+
+	for _, testTable := range testTables {
+		_, err := parseNamePolicy([]byte(testTable.rawNamePolicy))
+		testTable.errorAssertion(t, err, testTable.testName)
+	}
+}
+
+func Test_checkLibraryName(t *testing.T) {
+	specificationReference := "%0A%0ASee: https://arduino.github.io/arduino-cli/latest/library-specification/#libraryproperties-file-format"
+	faqReference := "%0A%0ASee: https://github.com/arduino/library-registry/blob/main/FAQ.md#what-are-the-requirements-for-a-library-to-be-added-to-library-manager"
+
+	testTables := []struct {
+		testName           string
+		name               string
+		official           bool
+		types              []string
+		expectedViolations []string
+	}{
+		{"Valid", "Foo Bar_Baz-1.2", false, []string{"Contributed"}, nil},
+		{"Maximum length", "Foooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooo", false, nil, nil},
+		{
+			"Too long",
+			"Foooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooo",
+			false,
+			nil,
+			[]string{"Library name `Foooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooo` is 65 characters long. The maximum length is 63 characters." + specificationReference},
+		},
+		{
+			"Invalid characters",
+			"Foo/Bär/Baz!",
+			false,
+			nil,
+			[]string{"Library name `Foo/Bär/Baz!` contains characters that are not allowed: `/`, `ä`, `!`. Names may only contain basic letters (A-Z or a-z), numbers (0-9), and ` _.-`." + specificationReference},
+		},
+		{"Invalid start", "_Foo", false, nil, []string{"Library name `_Foo` must start with a basic letter (A-Z or a-z) or number (0-9)." + specificationReference}},
+		{"Trailing space", "Foo ", false, nil, []string{"Library name `Foo ` must not end with a space." + specificationReference}},
+		{
+			"Reserved prefix",
+			"arduinoFoo",
+			false,
+			[]string{"Contributed"},
+			[]string{"Library names starting with `Arduino` are reserved for official Arduino libraries. Please change the `name` value in library.properties." + faqReference},
+		},
+		{"Reserved prefix, official", "ArduinoFoo", true, []string{"Arduino"}, nil},
+		{
+			"Reserved name",
+			"wire",
+			false,
+			nil,
+			[]string{"Library name `wire` is reserved because it is used by a library bundled with Arduino boards platforms. Please change the `name` value in library.properties." + faqReference},
+		},
+		{"Reserved name, official", "Wire", true, nil, nil},
+		{"Name containing reserved name", "WireFoo", false, nil, nil},
+	}
+
+	for _, testTable := range testTables {
+		assert.Equal(t, testTable.expectedViolations, checkLibraryName(testTable.name, testTable.official, testTable.types, defaultNamePolicy), testTable.testName)
+	}
+
+	namePolicy := defaultNamePolicy
+	namePolicy.Reserved = []reservedNamesType{{Prefixes: []string{"Foo"}, Types: []string{"Partner"}, Reason: "for partner libraries", Reference: "https://example.com"}}
+	assert.Nil(t, checkLibraryName("FooBar", false, []string{"Partner"}, namePolicy), "Allowed type")
+	assert.Equal(t, []string{"Library names starting with `Foo` are reserved for partner libraries. Please change the `name` value in library.properties.%0A%0ASee: https://example.com"}, checkLibraryName("FooBar", false, []string{"Contributed"}, namePolicy), "Other type")
+}
+
+func Test_populateSubmissionNamePolicy(t *testing.T) {
+	withTestRepository(t, map[string]map[string]string{"1.0.0": {"library.properties": "name=ArduinoFoo\nversion=1.0.0\n"}})
+	reservedPrefixError := "Library names starting with `Arduino` are reserved for official Arduino libraries. Please change the `name` value in library.properties.%0A%0ASee: https://github.com/arduino/library-registry/blob/main/FAQ.md#what-are-the-requirements-for-a-library-to-be-added-to-library-manager"
+
+	submission, _, err := populateTestSubmission(listChangeType{URL: "https://github.com/foo/bar"}, submissionOptionsType{namePolicy: &defaultNamePolicy})
+	require.Nil(t, err)
+	assert.Equal(t, reservedPrefixError, submission.Error, "Name policy")
+
+	submission, _, _, err = populateSubmission(listChangeType{URL: "https://github.com/foo/bar"}, nil, []libraryTypeDataType{{Name: "Arduino", Official: true, Default: true}}, nil, Default, nil, submissionOptionsType{namePolicy: &defaultNamePolicy})
+	require.Nil(t, err)
+	assert.Equal(t, "", submission.Error, "Official library")
+
+	submission, _, err = populateTestSubmission(listChangeType{URL: "https://github.com/foo/bar", ReplacedURL: "https://github.com/foo/baz"}, submissionOptionsType{namePolicy: &defaultNamePolicy})
+	require.Nil(t, err)
+	assert.Equal(t, reservedPrefixError, submission.Error, "Modification without index")
+}
@@ -0,0 +1,191 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// similarNameType is the type of the data for a Library Manager library with a name similar to the submission's.
+type similarNameType struct {
+	Name       string `json:"name"`       // Name of the library in Library Manager.
+	Repository string `json:"repository"` // Normalized URL of the library's repository.
+	Distance   int    `json:"distance"`   // Edit distance between the skeletons of the names. 0 means the names look alike.
+}
+
+// confusableCharacters maps non-ASCII characters to the ASCII letter they are visually confusable with.
+var confusableCharacters = map[rune]rune{
+	'ǀ': 'l', 'Ι': 'l', 'І': 'l', 'ӏ': 'l',
+	'О': 'o', 'о': 'o', 'Ο': 'o', 'ο': 'o',
+	'А': 'a', 'а': 'a', 'Α': 'a', 'α': 'a',
+	'В': 'b', 'Β': 'b', 'Ь': 'b',
+	'С': 'c', 'с': 'c', 'ϲ': 'c',
+	'Е': 'e', 'е': 'e', 'Ε': 'e',
+	'Н': 'h', 'Η': 'h', 'һ': 'h',
+	'і': 'i', 'ι': 'i',
+	'Ј': 'j', 'ј': 'j',
+	'К': 'k', 'Κ': 'k',
+	'М': 'm', 'Μ': 'm',
+	'Ν': 'n',
+	'Р': 'p', 'р': 'p', 'Ρ': 'p', 'ρ': 'p',
+	'Ѕ': 's', 'ѕ': 's',
+	'Т': 't', 'Τ': 't',
+	'ν': 'v',
+	'Ԝ': 'w', 'ԝ': 'w',
+	'Х': 'x', 'х': 'x', 'Χ': 'x', 'χ': 'x',
+	'У': 'y', 'у': 'y', 'Υ': 'y', 'γ': 'y',
+	'Ζ': 'z',
+}
+
+// similarCharacters maps ASCII characters to the character they look similar to in some fonts. Unlike the confusable
+// characters, these are also used in legitimate names (e.g., `Sensor10`), so they only make a name confusable when
+// substituting them reproduces the spelling of an existing name.
+var similarCharacters = map[rune]rune{
+	'I': 'l', '1': 'l', '|': 'l',
+	'0': 'o',
+}
+
+// confusableSkeleton returns the form of the library name used to detect impersonation: confusable characters are
+// replaced, letters are case folded, and separators are removed.
+func confusableSkeleton(name string) string {
+	var skeleton strings.Builder
+	for _, character := range name {
+		if replacement, ok := confusableCharacters[character]; ok {
+			character = replacement
+		}
+		if unicode.IsSpace(character) || strings.ContainsRune("_-.", character) {
+			continue
+		}
+		skeleton.WriteRune(unicode.ToLower(character))
+	}
+
+	return skeleton.String()
+}
+
+// nameSkeleton returns the form of the library name used to find similar names: in addition to the confusable
+// skeleton, similar characters are replaced and letter sequences that look like a single letter are folded.
+func nameSkeleton(name string) string {
+	name = strings.Map(func(character rune) rune {
+		if replacement, ok := similarCharacters[character]; ok {
+			return replacement
+		}
+		return character
+	}, name)
+
+	return strings.NewReplacer("rn", "m", "vv", "w").Replace(confusableSkeleton(name))
+}
+
+// editDistance returns the Levenshtein distance between the strings.
+func editDistance(a string, b string) int {
+	aRunes := []rune(a)
+	bRunes := []rune(b)
+	previousRow := make([]int, len(bRunes)+1)
+	for bIndex := range previousRow {
+		previousRow[bIndex] = bIndex
+	}
+	for aIndex := range aRunes {
+		row := make([]int, len(bRunes)+1)
+		row[0] = aIndex + 1
+		for bIndex := range bRunes {
+			substitutionCost := 1
+			if aRunes[aIndex] == bRunes[bIndex] {
+				substitutionCost = 0
+			}
+			row[bIndex+1] = min(previousRow[bIndex+1]+1, row[bIndex]+1, previousRow[bIndex]+substitutionCost)
+		}
+		previousRow = row
+	}
+
+	return previousRow[len(bRunes)]
+}
+
+// similarNames returns the libraries in the index, other than those of the excluded repositories, with names whose
+// skeleton is within the edit distance of the skeleton of the name, ordered from most to least similar. skeletonOf is
+// the function used to compute the skeletons.
+func similarNames(name string, libraryIndex *libraryIndexType, skeletonOf func(string) string, maxDistance int, excludedRepositories ...string) []similarNameType {
+	var matches []similarNameType
+	skeleton := skeletonOf(name)
+	for repository, indexName := range libraryIndex.names {
+		if slices.Contains(excludedRepositories, repository) {
+			continue
+		}
+		if distance := editDistance(skeleton, skeletonOf(indexName)); distance <= maxDistance {
+			matches = append(matches, similarNameType{Name: indexName, Repository: repository, Distance: distance})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+		return matches[i].Name < matches[j].Name
+	})
+
+	return matches
+}
+
+// checkSimilarNames records the libraries in the index with names similar to the submission's, and rejects or flags the
+// submission according to the similarity thresholds. Names whose confusable skeletons are within the error distance are
+// rejected, as are names that reproduce the spelling of an existing name through similar ASCII characters (e.g.,
+// `Adafruit_NeoPixeI`). The other similarities also occur between unrelated names (e.g., `Sensor10` and `SensorIO`), so
+// they are only flagged. It returns whether the submission was rejected.
+func checkSimilarNames(submission *submissionType, libraryIndex *libraryIndexType, similarityPolicy similarityPolicyType) bool {
+	maxDistance := max(similarityPolicy.ErrorDistance, similarityPolicy.WarningDistance)
+	if maxDistance < 0 {
+		return false
+	}
+
+	// The submission's own entry, and the entry it replaces, are not impersonated.
+	excludedRepositories := []string{submission.NormalizedURL}
+	if submission.OldURL != "" {
+		if normalizedReplacedURL, err := normalizeReplacedURL(submission.OldURL); err == nil {
+			excludedRepositories = append(excludedRepositories, normalizedReplacedURL.String())
+		}
+	}
+
+	submission.SimilarNames = similarNames(submission.Name, libraryIndex, nameSkeleton, maxDistance, excludedRepositories...)
+	if similarityPolicy.ErrorDistance >= 0 {
+		confusableNames := similarNames(submission.Name, libraryIndex, confusableSkeleton, similarityPolicy.ErrorDistance, excludedRepositories...)
+		skeleton := nameSkeleton(submission.Name)
+		for _, match := range submission.SimilarNames {
+			if match.Distance == 0 && skeleton == confusableSkeleton(match.Name) {
+				confusableNames = append(confusableNames, match)
+			}
+		}
+		if len(confusableNames) > 0 {
+			match := confusableNames[0]
+			submission.Error = fmt.Sprintf("Library name `%s` is too similar to the name of library `%s` (%s), which is already in Library Manager.%%0AThe name of each library in Library Manager must be distinct. Please change the `name` value in library.properties.%%0A%%0ASee: %s", submission.Name, match.Name, match.Repository, similarityPolicy.Reference)
+			return true
+		}
+	}
+	for _, match := range submission.SimilarNames {
+		if match.Distance <= similarityPolicy.WarningDistance {
+			submission.Warnings = append(submission.Warnings, fmt.Sprintf("Library name `%s` is similar to the name of library `%s` (%s), which is already in Library Manager.", submission.Name, match.Name, match.Repository))
+		}
+	}
+
+	return false
+}
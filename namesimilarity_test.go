@@ -0,0 +1,175 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_nameSkeleton(t *testing.T) {
+	testTables := []struct {
+		name             string
+		expectedSkeleton string
+	}{
+		{"Adafruit_NeoPixel", "adafruitneopixel"},
+		{"Adafruit_NeoPixeI", "adafruitneopixel"},
+		{"Adafruit NeoPixel", "adafruitneopixel"},
+		{"Servo ", "servo"},
+		{"Serv0", "servo"},
+		{"Ѕеrvо", "servo"},
+		{"Arduino-Modbus", "arduinomodbus"},
+		{"Firmata", "firmata"},
+		{"Firrnata", "firmata"},
+		{"VVire", "wire"},
+	}
+
+	for _, testTable := range testTables {
+		assert.Equal(t, testTable.expectedSkeleton, nameSkeleton(testTable.name), testTable.name)
+	}
+}
+
+func Test_confusableSkeleton(t *testing.T) {
+	testTables := []struct {
+		name             string
+		expectedSkeleton string
+	}{
+		{"Adafruit_NeoPixel", "adafruitneopixel"},
+		{"Adafruit NeoPixel", "adafruitneopixel"},
+		{"Ѕеrvо", "servo"},
+		{"Serv0", "serv0"},
+		{"SensorIO", "sensorio"},
+		{"Firrnata", "firrnata"},
+		{"VVire", "vvire"},
+	}
+
+	for _, testTable := range testTables {
+		assert.Equal(t, testTable.expectedSkeleton, confusableSkeleton(testTable.name), testTable.name)
+	}
+}
+
+func Test_editDistance(t *testing.T) {
+	testTables := []struct {
+		a                string
+		b                string
+		expectedDistance int
+	}{
+		{"", "", 0},
+		{"servo", "servo", 0},
+		{"servo", "", 5},
+		{"", "servo", 5},
+		{"servo", "serv", 1},
+		{"servo", "servos", 1},
+		{"servo", "sarvo", 1},
+		{"servo", "svreo", 2},
+		{"kitten", "sitting", 3},
+		{"ѕervo", "servo", 1},
+	}
+
+	for _, testTable := range testTables {
+		assert.Equal(t, testTable.expectedDistance, editDistance(testTable.a, testTable.b), testTable.a+" "+testTable.b)
+	}
+}
+
+func Test_similarNames(t *testing.T) {
+	libraryIndex, err := parseLibraryIndex([]byte(`https://github.com/adafruit/Adafruit_NeoPixel.git|Recommended|Adafruit NeoPixel
+https://github.com/arduino-libraries/Servo.git|Arduino|Servo
+https://github.com/foo/servos.git|Contributed|Servos
+https://github.com/arduino-libraries/Stepper.git|Arduino|Stepper
+`))
+	require.Nil(t, err)
+
+	assert.Equal(t, []similarNameType{{Name: "Adafruit NeoPixel", Repository: "https://github.com/adafruit/Adafruit_NeoPixel.git", Distance: 0}}, similarNames("Adafruit_NeoPixeI", libraryIndex, nameSkeleton, 1))
+	assert.Equal(
+		t,
+		[]similarNameType{
+			{Name: "Servo", Repository: "https://github.com/arduino-libraries/Servo.git", Distance: 0},
+			{Name: "Servos", Repository: "https://github.com/foo/servos.git", Distance: 1},
+		},
+		similarNames("Servo ", libraryIndex, nameSkeleton, 1),
+		"Ordered by similarity",
+	)
+	assert.Equal(t, []similarNameType{{Name: "Servo", Repository: "https://github.com/arduino-libraries/Servo.git", Distance: 0}}, similarNames("Servo ", libraryIndex, nameSkeleton, 0), "Maximum distance")
+	assert.Equal(t, []similarNameType{{Name: "Servos", Repository: "https://github.com/foo/servos.git", Distance: 1}}, similarNames("Servo", libraryIndex, nameSkeleton, 1, "https://github.com/arduino-libraries/Servo.git"), "Excluded repository")
+	assert.Nil(t, similarNames("FooBar", libraryIndex, nameSkeleton, 1), "No matches")
+	assert.Nil(t, similarNames("Adafruit_NeoPixeI", libraryIndex, confusableSkeleton, 0), "Skeleton function")
+}
+
+func Test_checkSimilarNames(t *testing.T) {
+	libraryIndex, err := parseLibraryIndex([]byte(`https://github.com/arduino-libraries/Servo.git|Arduino|Servo
+https://github.com/foo/servos.git|Contributed|Servos
+`))
+	require.Nil(t, err)
+
+	submission := submissionType{Name: "Ѕеrvо", NormalizedURL: "https://github.com/bar/servo.git"}
+	assert.True(t, checkSimilarNames(&submission, libraryIndex, defaultNamePolicy.Similarity), "Confusable")
+	assert.Equal(t, "Library name `Ѕеrvо` is too similar to the name of library `Servo` (https://github.com/arduino-libraries/Servo.git), which is already in Library Manager.%0AThe name of each library in Library Manager must be distinct. Please change the `name` value in library.properties.%0A%0ASee: https://arduino.github.io/arduino-cli/latest/library-specification/#libraryproperties-file-format", submission.Error)
+	assert.Len(t, submission.SimilarNames, 2, "Matching libraries are included in the output")
+
+	submission = submissionType{Name: "Serv0", NormalizedURL: "https://github.com/bar/servo.git"}
+	assert.True(t, checkSimilarNames(&submission, libraryIndex, defaultNamePolicy.Similarity), "Similar ASCII characters")
+	assert.Equal(t, "Library name `Serv0` is too similar to the name of library `Servo` (https://github.com/arduino-libraries/Servo.git), which is already in Library Manager.%0AThe name of each library in Library Manager must be distinct. Please change the `name` value in library.properties.%0A%0ASee: https://arduino.github.io/arduino-cli/latest/library-specification/#libraryproperties-file-format", submission.Error)
+	assert.Equal(t, []similarNameType{{Name: "Servo", Repository: "https://github.com/arduino-libraries/Servo.git", Distance: 0}, {Name: "Servos", Repository: "https://github.com/foo/servos.git", Distance: 1}}, submission.SimilarNames)
+
+	submission = submissionType{Name: "Servox", NormalizedURL: "https://github.com/bar/servo.git"}
+	assert.False(t, checkSimilarNames(&submission, libraryIndex, defaultNamePolicy.Similarity), "Similar")
+	assert.Equal(t, "", submission.Error)
+	assert.Equal(
+		t,
+		[]string{
+			"Library name `Servox` is similar to the name of library `Servo` (https://github.com/arduino-libraries/Servo.git), which is already in Library Manager.",
+			"Library name `Servox` is similar to the name of library `Servos` (https://github.com/foo/servos.git), which is already in Library Manager.",
+		},
+		submission.Warnings,
+	)
+
+	submission = submissionType{Name: "Ѕеrvо", NormalizedURL: "https://github.com/bar/servo.git"}
+	assert.False(t, checkSimilarNames(&submission, libraryIndex, similarityPolicyType{ErrorDistance: -1, WarningDistance: 0}), "Error threshold disabled")
+	assert.Equal(t, []string{"Library name `Ѕеrvо` is similar to the name of library `Servo` (https://github.com/arduino-libraries/Servo.git), which is already in Library Manager."}, submission.Warnings)
+
+	submission = submissionType{Name: "Serv0", NormalizedURL: "https://github.com/bar/servo.git"}
+	assert.False(t, checkSimilarNames(&submission, libraryIndex, similarityPolicyType{ErrorDistance: -1, WarningDistance: -1}), "Thresholds disabled")
+	assert.Nil(t, submission.SimilarNames)
+
+	submission = submissionType{Name: "Serv0", NormalizedURL: "https://github.com/bar/servo.git", OldURL: "https://github.com/arduino-libraries/Servo"}
+	assert.False(t, checkSimilarNames(&submission, libraryIndex, defaultNamePolicy.Similarity), "Replaced library")
+	assert.Equal(t, []similarNameType{{Name: "Servos", Repository: "https://github.com/foo/servos.git", Distance: 1}}, submission.SimilarNames)
+
+	libraryIndex, err = parseLibraryIndex([]byte(`https://github.com/adafruit/Adafruit_NeoPixel.git|Contributed|Adafruit_NeoPixel
+https://github.com/foo/modem.git|Contributed|Modem
+https://github.com/foo/sensorio.git|Contributed|SensorIO
+`))
+	require.Nil(t, err)
+	for _, name := range []string{"Adafruit_NeoPixeI", "Modern"} {
+		submission = submissionType{Name: name, NormalizedURL: "https://github.com/bar/baz.git"}
+		assert.True(t, checkSimilarNames(&submission, libraryIndex, defaultNamePolicy.Similarity), name)
+		assert.NotEqual(t, "", submission.Error, name)
+	}
+	for _, name := range []string{"Sensor10", "Adafruit_NeoPixels"} {
+		submission = submissionType{Name: name, NormalizedURL: "https://github.com/bar/baz.git"}
+		assert.False(t, checkSimilarNames(&submission, libraryIndex, defaultNamePolicy.Similarity), name)
+		assert.Equal(t, "", submission.Error, name)
+		assert.Len(t, submission.Warnings, 1, name)
+	}
+}
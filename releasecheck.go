@@ -0,0 +1,90 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/arduino/go-paths-helper"
+	properties "github.com/arduino/go-properties-orderedmap"
+)
+
+// releaseCheckType is the type of the data for the indexability check of a tagged release.
+type releaseCheckType struct {
+	Tag       string   `json:"tag"`       // Release tag name.
+	Name      string   `json:"name"`      // library.properties `name` value.
+	Version   string   `json:"version"`   // library.properties `version` value.
+	Indexable bool     `json:"indexable"` // Whether the Library Manager indexer will accept the release.
+	Problems  []string `json:"problems"`  // Reasons the release will be skipped by the indexer.
+}
+
+// checkReleases checks whether the Library Manager indexer will accept the release of each version tag of the
+// repository cloned at the path. The releases are returned in order of descending version. Each tag is checked out,
+// so this must be done after all other use of the clone.
+func checkReleases(clonePath *paths.Path, tags []string, libraryName string) ([]releaseCheckType, error) {
+	versionTags, _, _ := sortVersionTags(tags)
+	releaseChecks := []releaseCheckType{}
+	for _, tag := range versionTags {
+		err := gitClient.Checkout(clonePath, tag)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to check out tag %s: %s", tag, err)
+		}
+		releaseChecks = append(releaseChecks, checkRelease(clonePath, tag, libraryName))
+	}
+
+	return releaseChecks, nil
+}
+
+// checkRelease checks whether the Library Manager indexer will accept the release checked out at the path.
+func checkRelease(clonePath *paths.Path, tag string, libraryName string) releaseCheckType {
+	releaseCheck := releaseCheckType{Tag: tag, Problems: []string{}}
+	libraryPropertiesPath := clonePath.Join("library.properties")
+	if !libraryPropertiesPath.Exist() {
+		releaseCheck.Problems = append(releaseCheck.Problems, "library.properties is missing.")
+		return releaseCheck
+	}
+	libraryProperties, err := properties.LoadFromPath(libraryPropertiesPath)
+	if err != nil {
+		releaseCheck.Problems = append(releaseCheck.Problems, fmt.Sprintf("library.properties is invalid: %s", err))
+		return releaseCheck
+	}
+
+	var ok bool
+	releaseCheck.Name, ok = libraryProperties.GetOk("name")
+	if !ok {
+		releaseCheck.Problems = append(releaseCheck.Problems, "library.properties is missing the name field.")
+	} else if releaseCheck.Name != libraryName {
+		releaseCheck.Problems = append(releaseCheck.Problems, fmt.Sprintf("Name `%s` does not match the library name `%s`.", releaseCheck.Name, libraryName))
+	}
+
+	releaseCheck.Version, ok = libraryProperties.GetOk("version")
+	if !ok {
+		releaseCheck.Problems = append(releaseCheck.Problems, "library.properties is missing the version field.")
+	} else if _, err := parseVersion(releaseCheck.Version); err != nil {
+		releaseCheck.Problems = append(releaseCheck.Problems, fmt.Sprintf("Version `%s` is not compliant with the semver specification.", releaseCheck.Version))
+	} else if versionTagMismatchError(releaseCheck.Version, tag) != "" {
+		releaseCheck.Problems = append(releaseCheck.Problems, fmt.Sprintf("Version `%s` does not match the tag.", releaseCheck.Version))
+	}
+
+	releaseCheck.Indexable = len(releaseCheck.Problems) == 0
+	return releaseCheck
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/arduino/go-paths-helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_checkReleases(t *testing.T) {
+	originalGitClient := gitClient
+	defer func() { gitClient = originalGitClient }()
+	gitClient = &fakeGitClient{tags: map[string]map[string]string{
+		"v2.0.0":    {"library.properties": "name=Foo\nversion=2.0.0\n"},
+		"1.10.0":    {"library.properties": "name=Bar\nversion=1.10.0\n"},
+		"1.9.0":     {"library.properties": "name=Foo\nversion=1.9\n"},
+		"1.2.0":     {"library.properties": "name=Foo\nversion=foo\n"},
+		"1.1.0":     {"library.properties": "version=1.1.0\n"},
+		"1.0.0":     {"README.md": ""},
+		"0.1.0-rc1": {"library.properties": "name=Foo\nversion=0.1.0-rc1\n"},
+		"test":      {"library.properties": "name=Foo\nversion=1.0.0\n"},
+	}}
+
+	clonePath, err := paths.MkTempDir("", "")
+	require.Nil(t, err)
+	defer clonePath.RemoveAll()
+	tags := []string{"test", "1.0.0", "1.1.0", "1.2.0", "1.9.0", "1.10.0", "v2.0.0", "0.1.0-rc1"}
+
+	releaseChecks, err := checkReleases(clonePath, tags, "Foo")
+	require.Nil(t, err)
+	assert.Equal(
+		t,
+		[]releaseCheckType{
+			{Tag: "v2.0.0", Name: "Foo", Version: "2.0.0", Indexable: true, Problems: []string{}},
+			{Tag: "1.10.0", Name: "Bar", Version: "1.10.0", Problems: []string{"Name `Bar` does not match the library name `Foo`."}},
+			{Tag: "1.9.0", Name: "Foo", Version: "1.9", Problems: []string{"Version `1.9` does not match the tag."}},
+			{Tag: "1.2.0", Name: "Foo", Version: "foo", Problems: []string{"Version `foo` is not compliant with the semver specification."}},
+			{Tag: "1.1.0", Version: "1.1.0", Problems: []string{"library.properties is missing the name field."}},
+			{Tag: "1.0.0", Problems: []string{"library.properties is missing."}},
+			{Tag: "0.1.0-rc1", Name: "Foo", Version: "0.1.0-rc1", Indexable: true, Problems: []string{}},
+		},
+		releaseChecks,
+	)
+
+	_, err = checkReleases(clonePath, []string{"1.0.0", "3.0.0"}, "Foo")
+	assert.NotNil(t, err, "Checkout failure")
+}
+
+func Test_populateSubmissionDeep(t *testing.T) {
+	withTestRepository(t, map[string]map[string]string{
+		"1.1.0": {"library.properties": "name=Foo\nversion=1.1.0\n"},
+		"1.0.0": {"library.properties": "name=Bar\nversion=1.0.0\n"},
+	})
+
+	submission, indexEntry, err := populateTestSubmission(listChangeType{URL: "https://github.com/foo/bar"}, submissionOptionsType{deep: true})
+	require.Nil(t, err)
+	assert.Equal(t, "", submission.Error)
+	assert.Equal(t, "https://github.com/foo/bar.git|Contributed|Foo", indexEntry)
+	assert.Len(t, submission.Releases, 2)
+	assert.Equal(t, []string{"The Library Manager indexer will skip the releases of tags `1.0.0`."}, submission.Warnings)
+
+	submission, _, err = populateTestSubmission(listChangeType{URL: "https://github.com/foo/bar"}, submissionOptionsType{})
+	require.Nil(t, err)
+	assert.Nil(t, submission.Releases, "Releases are only checked in deep mode")
+	assert.Nil(t, submission.Warnings)
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// removalType is the type of the data for each library removed from the list by the request.
+type removalType struct {
+	RemovedURL              string `json:"removedURL"`              // Library repository URL as it was in the list.
+	File                    string `json:"file"`                    // Path of the list file.
+	Line                    int    `json:"line"`                    // Number of the line of the removed URL in the previous version of the list file.
+	NormalizedURL           string `json:"normalizedURL"`           // Removed URL in the standardized format used in the index.
+	Owner                   string `json:"owner"`                   // Slug of the account that owns the library repository (e.g., `github.com/arduino-libraries`).
+	SubmitterIsOwner        bool   `json:"submitterIsOwner"`        // Whether the submitter's account is the owner of the library repository.
+	SubmitterIsCollaborator bool   `json:"submitterIsCollaborator"` // Whether the submitter is a collaborator on the library repository.
+	CollaboratorUnknown     bool   `json:"collaboratorUnknown"`     // Whether the Git host didn't allow checking if the submitter is a collaborator, so a maintainer must review the removal.
+	Name                    string `json:"name"`                    // Library name recorded in the index.
+	Error                   string `json:"error"`                   // Problem encountered while gathering the data.
+}
+
+// populateRemoval gathers the data needed to review the removal of the URL from the list.
+func populateRemoval(listChange listChangeType, submitter string, libraryIndex *libraryIndexType) removalType {
+	var removal removalType
+	removal.RemovedURL = listChange.URL
+	removal.File = listChange.File
+	removal.Line = listChange.Line
+
+	removedURLObject, err := url.Parse(listChange.URL)
+	if err != nil {
+		removal.Error = fmt.Sprintf("Invalid removed URL (%s)", err)
+		return removal
+	}
+	gitHost, _ := gitHostProviderForURL(removedURLObject)
+	normalizedURLObject := gitHost.NormalizeURL(removedURLObject)
+	removal.NormalizedURL = normalizedURLObject.String()
+
+	if libraryIndex != nil {
+		removal.Name, _ = libraryIndex.nameForRepository(removal.NormalizedURL)
+	}
+
+	owner, repository, err := gitHost.OwnerAndRepository(normalizedURLObject)
+	if err != nil {
+		removal.Error = fmt.Sprintf("Unable to determine the owner of the library repository: %s", err)
+		return removal
+	}
+	removal.Owner = fmt.Sprintf("%s/%s", gitHost.Host(), owner)
+
+	// The submitter is identified by their GitHub username, so their relation to repositories on other hosts is unknown.
+	if gitHost.Host() != "github.com" {
+		return removal
+	}
+	removal.SubmitterIsOwner = strings.EqualFold(owner, submitter)
+	if removal.SubmitterIsOwner {
+		return removal
+	}
+
+	apiClient, ok := hostAPIClients[gitHost.Host()]
+	if !ok {
+		return removal
+	}
+	removal.SubmitterIsCollaborator, err = apiClient.IsCollaborator(owner, repository, submitter)
+	if errors.Is(err, errCollaboratorAccessDenied) {
+		removal.CollaboratorUnknown = true
+	} else if err != nil {
+		removal.Error = fmt.Sprintf("Unable to determine whether @%s is a collaborator on the library repository: %s", submitter, err)
+	}
+
+	return removal
+}
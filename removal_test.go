@@ -0,0 +1,129 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHostAPIClient is a HostAPIClient with canned responses.
+type fakeHostAPIClient struct {
+	collaborators map[string][]string // Collaborator usernames, indexed by `<owner>/<repository>`.
+	releaseTags   map[string][]string // Release tag names, indexed by `<owner>/<repository>`.
+	err           error               // Error returned by all methods.
+}
+
+func (client *fakeHostAPIClient) IsCollaborator(owner string, repository string, username string) (bool, error) {
+	if client.err != nil {
+		return false, client.err
+	}
+	for _, collaborator := range client.collaborators[owner+"/"+repository] {
+		if collaborator == username {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (client *fakeHostAPIClient) ReleaseTags(owner string, repository string) ([]string, error) {
+	if client.err != nil {
+		return nil, client.err
+	}
+
+	return client.releaseTags[owner+"/"+repository], nil
+}
+
+func Test_populateRemoval(t *testing.T) {
+	originalHostAPIClients := hostAPIClients
+	defer func() { hostAPIClients = originalHostAPIClients }()
+	hostAPIClients = map[string]HostAPIClient{
+		"github.com": &fakeHostAPIClient{collaborators: map[string][]string{"foo/bar": {"CollaboratorUser"}}},
+	}
+
+	libraryIndex, err := parseLibraryIndex([]byte("https://github.com/foo/bar.git|Contributed|Foo Bar\n"))
+	require.Nil(t, err)
+
+	testTables := []struct {
+		testName        string
+		removedURL      string
+		submitter       string
+		libraryIndex    *libraryIndexType
+		expectedRemoval removalType
+	}{
+		{
+			"Owner",
+			"https://github.com/foo/bar",
+			"FOO",
+			libraryIndex,
+			removalType{RemovedURL: "https://github.com/foo/bar", NormalizedURL: "https://github.com/foo/bar.git", Owner: "github.com/foo", SubmitterIsOwner: true, Name: "Foo Bar"},
+		},
+		{
+			"Collaborator",
+			"https://github.com/foo/bar",
+			"CollaboratorUser",
+			libraryIndex,
+			removalType{RemovedURL: "https://github.com/foo/bar", NormalizedURL: "https://github.com/foo/bar.git", Owner: "github.com/foo", SubmitterIsCollaborator: true, Name: "Foo Bar"},
+		},
+		{
+			"Unrelated user, no index",
+			"https://github.com/foo/bar",
+			"SomeUser",
+			nil,
+			removalType{RemovedURL: "https://github.com/foo/bar", NormalizedURL: "https://github.com/foo/bar.git", Owner: "github.com/foo"},
+		},
+		{
+			"Other host",
+			"https://gitlab.com/foo/baz/bar",
+			"foo",
+			libraryIndex,
+			removalType{RemovedURL: "https://gitlab.com/foo/baz/bar", NormalizedURL: "https://gitlab.com/foo/baz/bar.git", Owner: "gitlab.com/foo/baz"},
+		},
+		{
+			"Not a repository URL",
+			"https://github.com/foo",
+			"foo",
+			libraryIndex,
+			removalType{RemovedURL: "https://github.com/foo", NormalizedURL: "https://github.com/foo.git", Error: "Unable to determine the owner of the library repository: URL https://github.com/foo.git is not in the format github.com/<owner>/<repository>"},
+		},
+	}
+
+	for _, testTable := range testTables {
+		assert.Equal(t, testTable.expectedRemoval, populateRemoval(listChangeType{URL: testTable.removedURL}, testTable.submitter, testTable.libraryIndex), testTable.testName)
+	}
+
+	hostAPIClients = map[string]HostAPIClient{"github.com": &fakeHostAPIClient{err: errors.New("API error")}}
+	removal := populateRemoval(listChangeType{URL: "https://github.com/foo/bar", File: "repositories.txt", Line: 42}, "SomeUser", nil)
+	assert.Equal(t, "repositories.txt", removal.File)
+	assert.Equal(t, 42, removal.Line)
+	assert.False(t, removal.SubmitterIsCollaborator)
+	assert.Equal(t, "Unable to determine whether @SomeUser is a collaborator on the library repository: API error", removal.Error)
+
+	hostAPIClients = map[string]HostAPIClient{"github.com": &fakeHostAPIClient{err: errCollaboratorAccessDenied}}
+	removal = populateRemoval(listChangeType{URL: "https://github.com/foo/bar"}, "SomeUser", nil)
+	assert.True(t, removal.CollaboratorUnknown, "No access to the repository's collaborators")
+	assert.Equal(t, "", removal.Error)
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// tagSelectionRuleType is the type of the identifiers for the rules used to select the release tag.
+type tagSelectionRuleType string
+
+const (
+	// HighestStableVersionRule means the tag with the highest stable release version was selected.
+	HighestStableVersionRule tagSelectionRuleType = "highest-stable-version"
+	// HighestPrereleaseVersionRule means the tag with the highest pre-release version was selected, because there are no
+	// stable release version tags.
+	HighestPrereleaseVersionRule tagSelectionRuleType = "highest-prerelease-version"
+	// MostRecentTagRule means the tag of the most recent tagged commit was selected, because there are no version tags.
+	MostRecentTagRule tagSelectionRuleType = "most-recent-tag"
+)
+
+// tagSelectionType is the type of the data explaining how the submission's release tag was selected.
+type tagSelectionType struct {
+	Rule        tagSelectionRuleType `json:"rule"`                  // Rule used to select the tag.
+	Explanation string               `json:"explanation"`           // Description of why the tag was selected.
+	IgnoredTags []string             `json:"ignoredTags,omitempty"` // Tags that were not considered because they are not versions.
+}
+
+// selectReleaseTag selects the tag of the library release from the repository's tags. Tags that are versions, optionally
+// with a `v` prefix, are preferred, with stable releases preferred over pre-releases. If releaseTags is not empty, only
+// the version tags of releases are considered, unless none of the releases have a version tag. mostRecentTag is the tag
+// of the most recent tagged commit, which is used if there are no version tags.
+func selectReleaseTag(tags []string, releaseTags []string, mostRecentTag string) (string, tagSelectionType) {
+	var selection tagSelectionType
+	versionTags, tagVersions, otherTags := sortVersionTags(tags)
+	selection.IgnoredTags = otherTags
+
+	if len(versionTags) == 0 {
+		selection.Rule = MostRecentTagRule
+		selection.Explanation = fmt.Sprintf("Tag `%s` is on the most recent tagged commit. There are no version tags.", mostRecentTag)
+		return mostRecentTag, selection
+	}
+
+	candidateTags := versionTags
+	candidatesDescription := "tags"
+	var releaseVersionTags []string
+	for _, tag := range versionTags {
+		for _, releaseTag := range releaseTags {
+			if tag == releaseTag {
+				releaseVersionTags = append(releaseVersionTags, tag)
+				break
+			}
+		}
+	}
+	if len(releaseVersionTags) > 0 {
+		candidateTags = releaseVersionTags
+		candidatesDescription = "release tags"
+	}
+
+	for _, tag := range candidateTags {
+		if !tagVersions[tag].isPrerelease() {
+			selection.Rule = HighestStableVersionRule
+			selection.Explanation = fmt.Sprintf("Tag `%s` has the highest stable release version of the %s.", tag, candidatesDescription)
+			return tag, selection
+		}
+	}
+
+	selection.Rule = HighestPrereleaseVersionRule
+	selection.Explanation = fmt.Sprintf("Tag `%s` has the highest pre-release version of the %s. There are no stable release version tags.", candidateTags[0], candidatesDescription)
+	return candidateTags[0], selection
+}
+
+// sortVersionTags returns the tags that are versions, optionally with a `v` prefix, in order of descending version, the
+// versions of those tags, and the other tags in alphabetical order. Equal versions (e.g., `1.0.0` and `v1.0.0`) are
+// sorted by name for a consistent result.
+func sortVersionTags(tags []string) ([]string, map[string]versionType, []string) {
+	var versionTags []string
+	var otherTags []string
+	tagVersions := make(map[string]versionType)
+	for _, tag := range tags {
+		version, err := parseVersion(strings.TrimPrefix(tag, "v"))
+		if err != nil {
+			otherTags = append(otherTags, tag)
+			continue
+		}
+		versionTags = append(versionTags, tag)
+		tagVersions[tag] = version
+	}
+
+	sort.Slice(versionTags, func(i, j int) bool {
+		if result := tagVersions[versionTags[i]].compare(tagVersions[versionTags[j]]); result != 0 {
+			return result > 0
+		}
+		return versionTags[i] < versionTags[j]
+	})
+	sort.Strings(otherTags)
+
+	return versionTags, tagVersions, otherTags
+}
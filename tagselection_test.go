@@ -0,0 +1,138 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_selectReleaseTag(t *testing.T) {
+	testTables := []struct {
+		testName          string
+		tags              []string
+		releaseTags       []string
+		mostRecentTag     string
+		expectedTag       string
+		expectedSelection tagSelectionType
+	}{
+		{
+			testName:      "Stable over pre-release and more recent non-version tags",
+			tags:          []string{"1.0.0", "v1.10.0", "1.9.0", "v2.0.0-rc1", "test"},
+			mostRecentTag: "test",
+			expectedTag:   "v1.10.0",
+			expectedSelection: tagSelectionType{
+				Rule:        HighestStableVersionRule,
+				Explanation: "Tag `v1.10.0` has the highest stable release version of the tags.",
+				IgnoredTags: []string{"test"},
+			},
+		},
+		{
+			testName:      "Pre-release only",
+			tags:          []string{"1.0.0-beta.2", "1.0.0-beta.10", "1.0.0-alpha"},
+			mostRecentTag: "1.0.0-beta.2",
+			expectedTag:   "1.0.0-beta.10",
+			expectedSelection: tagSelectionType{
+				Rule:        HighestPrereleaseVersionRule,
+				Explanation: "Tag `1.0.0-beta.10` has the highest pre-release version of the tags. There are no stable release version tags.",
+			},
+		},
+		{
+			testName:      "No version tags",
+			tags:          []string{"foo", "bar"},
+			mostRecentTag: "foo",
+			expectedTag:   "foo",
+			expectedSelection: tagSelectionType{
+				Rule:        MostRecentTagRule,
+				Explanation: "Tag `foo` is on the most recent tagged commit. There are no version tags.",
+				IgnoredTags: []string{"bar", "foo"},
+			},
+		},
+		{
+			testName:      "Equal versions",
+			tags:          []string{"v1.0.0", "1.0.0"},
+			mostRecentTag: "v1.0.0",
+			expectedTag:   "1.0.0",
+			expectedSelection: tagSelectionType{
+				Rule:        HighestStableVersionRule,
+				Explanation: "Tag `1.0.0` has the highest stable release version of the tags.",
+			},
+		},
+		{
+			testName:      "Releases",
+			tags:          []string{"1.0.0", "1.1.0", "2.0.0"},
+			releaseTags:   []string{"1.1.0", "1.0.0", "nightly"},
+			mostRecentTag: "2.0.0",
+			expectedTag:   "1.1.0",
+			expectedSelection: tagSelectionType{
+				Rule:        HighestStableVersionRule,
+				Explanation: "Tag `1.1.0` has the highest stable release version of the release tags.",
+			},
+		},
+		{
+			testName:      "No release version tags",
+			tags:          []string{"1.0.0", "nightly"},
+			releaseTags:   []string{"nightly"},
+			mostRecentTag: "nightly",
+			expectedTag:   "1.0.0",
+			expectedSelection: tagSelectionType{
+				Rule:        HighestStableVersionRule,
+				Explanation: "Tag `1.0.0` has the highest stable release version of the tags.",
+				IgnoredTags: []string{"nightly"},
+			},
+		},
+	}
+
+	for _, testTable := range testTables {
+		tag, selection := selectReleaseTag(testTable.tags, testTable.releaseTags, testTable.mostRecentTag)
+		assert.Equal(t, testTable.expectedTag, tag, testTable.testName)
+		assert.Equal(t, testTable.expectedSelection, selection, testTable.testName)
+	}
+}
+
+func Test_populateSubmissionTagSelection(t *testing.T) {
+	withTestRepository(t, map[string]map[string]string{
+		"1.0.0": {"library.properties": "name=Foo\nversion=1.0.0\n"},
+		"2.0.0": {"library.properties": "name=Foo\nversion=2.0.0\n"},
+	})
+	originalHostAPIClients := hostAPIClients
+	defer func() { hostAPIClients = originalHostAPIClients }()
+
+	hostAPIClients = map[string]HostAPIClient{"github.com": &fakeHostAPIClient{releaseTags: map[string][]string{"foo/bar": {"1.0.0"}}}}
+	submission, _, err := populateTestSubmission(listChangeType{URL: "https://github.com/foo/bar"}, submissionOptionsType{preferReleases: true})
+	require.Nil(t, err)
+	assert.Equal(t, "1.0.0", submission.Tag, "Release tag is preferred")
+	assert.Equal(t, HighestStableVersionRule, submission.TagSelection.Rule)
+
+	submission, _, err = populateTestSubmission(listChangeType{URL: "https://github.com/foo/bar"}, submissionOptionsType{})
+	require.Nil(t, err)
+	assert.Equal(t, "2.0.0", submission.Tag, "Releases are only used when enabled")
+
+	hostAPIClients = map[string]HostAPIClient{"github.com": &fakeHostAPIClient{err: errors.New("API error")}}
+	_, _, err = populateTestSubmission(listChangeType{URL: "https://github.com/foo/bar"}, submissionOptionsType{preferReleases: true})
+	var apiError *internalError
+	require.ErrorAs(t, err, &apiError)
+	assert.Equal(t, HostAPIError, apiError.Code)
+}
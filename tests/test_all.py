@@ -39,11 +39,17 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "https://github.com/sparkfun/SparkFun_Ublox_Arduino_Library",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "https://github.com/sparkfun/SparkFun_Ublox_Arduino_Library.git",
                     "repositoryName": "SparkFun_Ublox_Arduino_Library",
                     "name": "SparkFun u-blox Arduino Library",
                     "official": False,
                     "tag": "v1.8.11",
+                    "tagSelection": {
+                        "rule": "highest-stable-version",
+                        "explanation": "Tag `v1.8.11` has the highest stable release version of the tags.",
+                    },
                     "error": "",
                 }
             ],
@@ -87,12 +93,20 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "https://github.com/arduino-libraries/ArduinoCloudThing",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "https://github.com/arduino-libraries/ArduinoCloudThing.git",
                     "repositoryName": "ArduinoCloudThing",
                     "name": "ArduinoCloudThing",
                     "official": True,
                     "tag": "1.7.3",
+                    "tagSelection": {
+                        "rule": "highest-stable-version",
+                        "explanation": "Tag `1.7.3` has the highest stable release version of the tags.",
+                    },
                     "error": "",
+                    "oldURL": "https://github.com/arduino-libraries/Servo",
+                    "newURL": "https://github.com/arduino-libraries/ArduinoCloudThing",
                 }
             ],
             "https://github.com/arduino-libraries/ArduinoCloudThing.git|Arduino|ArduinoCloudThing",
@@ -107,6 +121,8 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "foo",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "",
                     "repositoryName": "",
                     "name": "",
@@ -127,6 +143,8 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "http://httpstat.us/404",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "",
                     "repositoryName": "",
                     "name": "",
@@ -147,6 +165,8 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "https://github.com/sparkfun/SparkFun_Ublox_Arduino_Library",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "https://github.com/sparkfun/SparkFun_Ublox_Arduino_Library.git",
                     "repositoryName": "",
                     "name": "",
@@ -168,6 +188,8 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "https://github.com/sparkfun/SparkFun_Ublox_Arduino_Library",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "https://github.com/sparkfun/SparkFun_Ublox_Arduino_Library.git",
                     "repositoryName": "",
                     "name": "",
@@ -178,11 +200,17 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
                 },
                 {
                     "submissionURL": "https://github.com/adafruit/Adafruit_TinyFlash",
+                    "file": "repositories.txt",
+                    "line": 2,
                     "normalizedURL": "https://github.com/adafruit/Adafruit_TinyFlash.git",
                     "repositoryName": "Adafruit_TinyFlash",
                     "name": "Adafruit TinyFlash",
                     "official": False,
                     "tag": "1.0.4",
+                    "tagSelection": {
+                        "rule": "highest-stable-version",
+                        "explanation": "Tag `1.0.4` has the highest stable release version of the tags.",
+                    },
                     "error": "",
                 },
             ],
@@ -199,6 +227,8 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "https://example.com",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "https://example.com/",
                     "repositoryName": "",
                     "name": "",
@@ -221,6 +251,8 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "https://github.com/arduino-libraries/ArduinoCloudThing/releases",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "https://github.com/arduino-libraries/ArduinoCloudThing/releases.git",
                     "repositoryName": "",
                     "name": "",
@@ -242,6 +274,8 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "https://github.com/arduino-libraries/Servo",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "https://github.com/arduino-libraries/Servo.git",
                     "repositoryName": "Servo",
                     "name": "",
@@ -262,6 +296,8 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "https://github.com/arduino-org/WiFi_for_UNOWiFi_rev1",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "https://github.com/arduino-libraries/WiFi_for_UNOWiFi_rev1.git",
                     "repositoryName": "WiFi_for_UNOWiFi_rev1",
                     "name": "",
@@ -283,11 +319,17 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "https://github.com/arduino-libraries/ArduinoCloudThing",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "https://github.com/arduino-libraries/ArduinoCloudThing.git",
                     "repositoryName": "ArduinoCloudThing",
                     "name": "ArduinoCloudThing",
                     "official": True,
                     "tag": "1.7.3",
+                    "tagSelection": {
+                        "rule": "highest-stable-version",
+                        "explanation": "Tag `1.7.3` has the highest stable release version of the tags.",
+                    },
                     "error": "",
                 }
             ],
@@ -303,11 +345,17 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "https://github.com/ms-iot/virtual-shields-arduino",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "https://github.com/ms-iot/virtual-shields-arduino.git",
                     "repositoryName": "virtual-shields-arduino",
                     "name": "Windows Virtual Shields for Arduino",
                     "official": False,
                     "tag": "v1.2.0",
+                    "tagSelection": {
+                        "rule": "highest-stable-version",
+                        "explanation": "Tag `v1.2.0` has the highest stable release version of the tags.",
+                    },
                     "error": "",
                 }
             ],
@@ -323,11 +371,17 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "https://github.com/adafruit/Adafruit_TinyFlash",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "https://github.com/adafruit/Adafruit_TinyFlash.git",
                     "repositoryName": "Adafruit_TinyFlash",
                     "name": "Adafruit TinyFlash",
                     "official": False,
                     "tag": "1.0.4",
+                    "tagSelection": {
+                        "rule": "highest-stable-version",
+                        "explanation": "Tag `1.0.4` has the highest stable release version of the tags.",
+                    },
                     "error": "",
                 }
             ],
@@ -343,11 +397,17 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "https://github.com/sparkfun/SparkFun_Ublox_Arduino_Library",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "https://github.com/sparkfun/SparkFun_Ublox_Arduino_Library.git",
                     "repositoryName": "SparkFun_Ublox_Arduino_Library",
                     "name": "SparkFun u-blox Arduino Library",
                     "official": False,
                     "tag": "v1.8.11",
+                    "tagSelection": {
+                        "rule": "highest-stable-version",
+                        "explanation": "Tag `v1.8.11` has the highest stable release version of the tags.",
+                    },
                     "error": "",
                 }
             ],
@@ -364,6 +424,8 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "https://github.com/arduino/cloud-examples",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "https://github.com/arduino/cloud-examples.git",
                     "repositoryName": "cloud-examples",
                     "name": "",
@@ -386,11 +448,17 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "https://github.com/arduino-libraries/WiFiLink-Firmware",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "https://github.com/arduino-libraries/WiFiLink-Firmware.git",
                     "repositoryName": "WiFiLink-Firmware",
                     "name": "",
                     "official": True,
                     "tag": "1.0.1",
+                    "tagSelection": {
+                        "rule": "highest-stable-version",
+                        "explanation": "Tag `1.0.1` has the highest stable release version of the tags.",
+                    },
                     "error": "Library is missing a library.properties metadata file.%0A%0A"
                     "See: https://arduino.github.io/arduino-cli/latest/library-specification/#library-metadata",
                 }
@@ -407,20 +475,32 @@ test_data_path = pathlib.Path(__file__).resolve().parent.joinpath("testdata")
             [
                 {
                     "submissionURL": "https://github.com/arduino-libraries/ArduinoCloudThing",
+                    "file": "repositories.txt",
+                    "line": 1,
                     "normalizedURL": "https://github.com/arduino-libraries/ArduinoCloudThing.git",
                     "repositoryName": "ArduinoCloudThing",
                     "name": "ArduinoCloudThing",
                     "official": True,
                     "tag": "1.7.3",
+                    "tagSelection": {
+                        "rule": "highest-stable-version",
+                        "explanation": "Tag `1.7.3` has the highest stable release version of the tags.",
+                    },
                     "error": "",
                 },
                 {
                     "submissionURL": "https://github.com/arduino-libraries/ArduinoCloudThing",
+                    "file": "repositories.txt",
+                    "line": 2,
                     "normalizedURL": "https://github.com/arduino-libraries/ArduinoCloudThing.git",
                     "repositoryName": "ArduinoCloudThing",
                     "name": "ArduinoCloudThing",
                     "official": True,
                     "tag": "1.7.3",
+                    "tagSelection": {
+                        "rule": "highest-stable-version",
+                        "explanation": "Tag `1.7.3` has the highest stable release version of the tags.",
+                    },
                     "error": "Submission contains duplicate URLs.",
                 },
             ],
@@ -443,14 +523,20 @@ def test_request(
     expected_indexerlogsurls,
 ):
     accesslist = ".github/workflows/assets/accesslist.yml"
+    typesconfig = "../types.yml"  # The types configuration is shared by all test cases.
     diffpath = test_data_path.joinpath(repopath_folder_name, "diff.txt")
     repopath = test_data_path.joinpath(repopath_folder_name)
     listname = "repositories.txt"
+    fixtures = test_data_path.joinpath("fixtures")  # Canned responses, so the tests don't use the network.
 
     result = run_command(
         cmd=[
             "--accesslist",
             accesslist,
+            "--typesconfig",
+            typesconfig,
+            "--fixtures",
+            fixtures,
             "--diffpath",
             diffpath,
             "--repopath",
@@ -471,6 +557,83 @@ def test_request(
     assert request["indexEntry"] == expected_indexentry
     assert request["submissions"] == expected_submissions
     assert request["indexerLogsURLs"] == expected_indexerlogsurls
+    assert request["internalError"] is None
+
+
+@pytest.mark.parametrize(
+    "repopath_folder_name, expected_ok, expected_output",
+    [
+        ("submitter-access-deny", True, ""),
+        (
+            "invalid-accesslist",
+            False,
+            ".github/workflows/assets/accesslist.yml:3: Invalid access level `denyy` (must be one of `allow`, `default`, "
+            "`deny`)\n"
+            ".github/workflows/assets/accesslist.yml:5: Missing required key `reference`\n"
+            ".github/workflows/assets/accesslist.yml:6: GitHub account name `denyuser` collides with `DenyUser` at line 2 "
+            "(GitHub account names are case-insensitive)\n",
+        ),
+    ],
+)
+def test_validate_accesslist(run_command, repopath_folder_name, expected_ok, expected_output):
+    accesslist = ".github/workflows/assets/accesslist.yml"
+    repopath = test_data_path.joinpath(repopath_folder_name)
+
+    result = run_command(cmd=["validate-accesslist", "--accesslist", accesslist, "--repopath", repopath])
+    assert result.ok == expected_ok
+    assert result.stdout == ""
+    assert result.stderr == expected_output
+
+
+@pytest.mark.parametrize(
+    "repopath_folder_name, expected_ok, expected_findings",
+    [
+        ("submitter-access-allow", True, []),
+        (
+            "invalid-list",
+            False,
+            [
+                {
+                    "line": 2,
+                    "check": "whitespace",
+                    "url": "https://github.com/arduino-libraries/Stepper",
+                    "message": "Line has leading or trailing whitespace.",
+                },
+                {
+                    "line": 3,
+                    "check": "duplicate",
+                    "url": "https://github.com/arduino-libraries/Servo.git",
+                    "message": "URL is a duplicate of line 1.",
+                },
+                {
+                    "line": 4,
+                    "check": "unsupported-host",
+                    "url": "https://example.com/foo/bar",
+                    "message": "`example.com` is not a supported Git host.",
+                },
+                {
+                    "line": 5,
+                    "check": "denied-owner",
+                    "url": "https://github.com/DenyUser/foo",
+                    "message": "Library registry privileges for library repository owner `github.com/DenyUser` have been "
+                    "revoked. See: https://example.com",
+                },
+            ],
+        ),
+    ],
+)
+def test_check_list(run_command, repopath_folder_name, expected_ok, expected_findings):
+    accesslist = ".github/workflows/assets/accesslist.yml"
+    repopath = test_data_path.joinpath(repopath_folder_name)
+    listname = "repositories.txt"
+
+    result = run_command(
+        cmd=["check-list", "--accesslist", accesslist, "--repopath", repopath, "--listname", listname]
+    )
+    assert result.ok == expected_ok
+    report = json.loads(result.stdout)
+    assert report["file"] == listname
+    assert report["findings"] == expected_findings
 
 
 @pytest.fixture(scope="function")
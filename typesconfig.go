@@ -0,0 +1,135 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// libraryTypeDataType is the type of the library type configuration data.
+type libraryTypeDataType struct {
+	Name     string   `yaml:"name"`     // Type name, as used in the index source entry (e.g., `Partner`).
+	Official bool     `yaml:"official"` // Whether libraries of this type are linted with Arduino Lint in the "official" setting.
+	Default  bool     `yaml:"default"`  // Whether this type is assigned to libraries that don't have any other type.
+	Owners   []string `yaml:"owners"`   // Slugs of the accounts whose libraries have this type (e.g., `github.com/arduino`).
+}
+
+// defaultTypesConfiguration is the library types configuration used when no types configuration file is provided.
+var defaultTypesConfiguration = []libraryTypeDataType{
+	{
+		Name:     "Arduino",
+		Official: true,
+		Owners:   []string{"github.com/arduino", "github.com/arduino-libraries", "github.com/bcmi-labs", "github.com/vidor-libraries"},
+	},
+	{
+		Name:   "Partner",
+		Owners: []string{"github.com/Azure", "github.com/ms-iot", "github.com/ameltech"},
+	},
+	{
+		Name:   "Recommended",
+		Owners: []string{"github.com/adafruit"},
+	},
+	{
+		Name:    "Contributed",
+		Default: true,
+	},
+}
+
+// parseTypesConfiguration parses and validates the library types configuration file data.
+func parseTypesConfiguration(rawTypesConfiguration []byte) ([]libraryTypeDataType, error) {
+	var typesConfiguration []libraryTypeDataType
+	decoder := yaml.NewDecoder(bytes.NewReader(rawTypesConfiguration))
+	decoder.KnownFields(true) // Catch misspelled keys, which would otherwise silently change the type assignments.
+	err := decoder.Decode(&typesConfiguration)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	typeNames := make(map[string]bool)
+	defaultTypeCount := 0
+	for typeIndex, libraryType := range typesConfiguration {
+		if libraryType.Name == "" {
+			return nil, fmt.Errorf("Type #%d has no name", typeIndex+1)
+		}
+		if strings.ContainsAny(libraryType.Name, ",|") {
+			return nil, fmt.Errorf("Type name %s contains an index source separator character (`,` or `|`)", libraryType.Name)
+		}
+		if typeNames[libraryType.Name] {
+			return nil, fmt.Errorf("Type %s is defined multiple times", libraryType.Name)
+		}
+		typeNames[libraryType.Name] = true
+
+		if libraryType.Default {
+			defaultTypeCount++
+			if len(libraryType.Owners) > 0 {
+				return nil, fmt.Errorf("Default type %s must not have owners", libraryType.Name)
+			}
+			continue
+		}
+
+		if len(libraryType.Owners) == 0 {
+			return nil, fmt.Errorf("Type %s has no owners", libraryType.Name)
+		}
+		for _, owner := range libraryType.Owners {
+			ownerURL, err := url.Parse("https://" + owner)
+			if err != nil || ownerURL.Host == "" || len(uRLPathElements(*ownerURL)) == 0 {
+				return nil, fmt.Errorf("Owner %s of type %s is not in the format <host>/<account name>", owner, libraryType.Name)
+			}
+		}
+	}
+
+	if defaultTypeCount != 1 {
+		return nil, fmt.Errorf("Exactly one default type must be defined, found %d", defaultTypeCount)
+	}
+
+	return typesConfiguration, nil
+}
+
+// libraryTypes returns the names of the types of the library at the normalized URL and whether it is official.
+func libraryTypes(normalizedURL url.URL, typesConfiguration []libraryTypeDataType) ([]string, bool) {
+	var types []string
+	official := false
+	for _, libraryType := range typesConfiguration {
+		if !libraryType.Default && uRLIsUnder(normalizedURL, libraryType.Owners) {
+			types = append(types, libraryType.Name)
+			official = official || libraryType.Official
+		}
+	}
+
+	if types == nil {
+		for _, libraryType := range typesConfiguration {
+			if libraryType.Default {
+				types = append(types, libraryType.Name)
+				official = libraryType.Official
+			}
+		}
+	}
+
+	return types, official
+}
@@ -0,0 +1,118 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var testTypesConfiguration = []byte(`
+- name: Arduino
+  official: true
+  owners:
+    - github.com/arduino
+    - github.com/arduino-libraries
+- name: Partner
+  owners:
+    - github.com/ms-iot
+- name: Recommended
+  owners:
+    - github.com/adafruit
+    - github.com/arduino-libraries
+- name: Contributed
+  default: true
+`)
+
+func Test_parseTypesConfiguration(t *testing.T) {
+	typesConfiguration, err := parseTypesConfiguration(testTypesConfiguration)
+	require.Nil(t, err)
+	require.Len(t, typesConfiguration, 4)
+	assert.Equal(t, libraryTypeDataType{Name: "Arduino", Official: true, Owners: []string{"github.com/arduino", "github.com/arduino-libraries"}}, typesConfiguration[0])
+	assert.Equal(t, libraryTypeDataType{Name: "Contributed", Default: true}, typesConfiguration[3])
+
+	testTables := []struct {
+		testName              string
+		rawTypesConfiguration string
+	}{
+		{"Empty", ""},
+		{"Invalid YAML", "- name: [Foo"},
+		{"Unknown key", "- name: Contributed\n  default: true\n- name: Foo\n  owner:\n    - github.com/foo\n"},
+		{"Missing name", "- name: Contributed\n  default: true\n- owners:\n    - github.com/foo\n"},
+		{"Separator in name", "- name: Contributed\n  default: true\n- name: Foo|Bar\n  owners:\n    - github.com/foo\n"},
+		{"Duplicate name", "- name: Contributed\n  default: true\n- name: Contributed\n  owners:\n    - github.com/foo\n"},
+		{"No owners", "- name: Contributed\n  default: true\n- name: Foo\n"},
+		{"Invalid owner", "- name: Contributed\n  default: true\n- name: Foo\n  owners:\n    - github.com\n"},
+		{"No default", "- name: Foo\n  owners:\n    - github.com/foo\n"},
+		{"Multiple defaults", "- name: Contributed\n  default: true\n- name: Foo\n  default: true\n"},
+		{"Default with owners", "- name: Contributed\n  default: true\n  owners:\n    - github.com/foo\n"},
+	}
+
+	for _, testTable := range testTables {
+		_, err := parseTypesConfiguration([]byte(testTable.rawTypesConfiguration))
+		assert.NotNil(t, err, testTable.testName)
+	}
+}
+
+func Test_defaultTypesConfiguration(t *testing.T) {
+	rawTypesConfiguration, err := yaml.Marshal(defaultTypesConfiguration)
+	require.Nil(t, err)
+	_, err = parseTypesConfiguration(rawTypesConfiguration)
+	assert.Nil(t, err, "Built-in configuration is valid")
+
+	normalizedURL, err := url.Parse("https://github.com/arduino-libraries/Servo.git")
+	require.Nil(t, err)
+	types, official := libraryTypes(*normalizedURL, defaultTypesConfiguration)
+	assert.Equal(t, []string{"Arduino"}, types)
+	assert.True(t, official)
+}
+
+func Test_libraryTypes(t *testing.T) {
+	typesConfiguration, err := parseTypesConfiguration(testTypesConfiguration)
+	require.Nil(t, err)
+
+	testTables := []struct {
+		testName         string
+		normalizedURL    string
+		expectedTypes    []string
+		expectedOfficial assert.BoolAssertionFunc
+	}{
+		{"Official", "https://github.com/arduino/foo.git", []string{"Arduino"}, assert.True},
+		{"Partner", "https://github.com/ms-iot/foo.git", []string{"Partner"}, assert.False},
+		{"Multiple types", "https://github.com/arduino-libraries/foo.git", []string{"Arduino", "Recommended"}, assert.True},
+		{"Default", "https://github.com/foo/bar.git", []string{"Contributed"}, assert.False},
+		{"Owner name prefix", "https://github.com/arduinofoo/bar.git", []string{"Contributed"}, assert.False},
+	}
+
+	for _, testTable := range testTables {
+		normalizedURL, err := url.Parse(testTable.normalizedURL)
+		require.Nil(t, err)
+
+		types, official := libraryTypes(*normalizedURL, typesConfiguration)
+		assert.Equal(t, testTable.expectedTypes, types, testTable.testName)
+		testTable.expectedOfficial(t, official, testTable.testName)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arduino/go-paths-helper"
+	"gopkg.in/yaml.v3"
+)
+
+// accessListDiagnosticType is the type of the data for a problem found in the access control file.
+type accessListDiagnosticType struct {
+	Line    int    // Line number in the access control file.
+	Message string // Description of the problem.
+}
+
+// yamlErrorLineRegexp matches the line number in the YAML parser's error messages.
+var yamlErrorLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+// validateAccessListCommand implements the `validate-accesslist` subcommand, which checks the access control file for
+// problems, writes them to stderr, and exits with status 1 if any are found.
+func validateAccessListCommand(arguments []string) {
+	flagSet := flag.NewFlagSet("validate-accesslist", flag.ExitOnError)
+	// Path of the access control file, relative to repopath.
+	accesslistArgument := flagSet.String("accesslist", "", "")
+	repoPathArgument := flagSet.String("repopath", "", "")
+	flagSet.Parse(arguments)
+
+	if *accesslistArgument == "" {
+		errorExit("--accesslist flag is required")
+	}
+
+	if *repoPathArgument == "" {
+		errorExit("--repopath flag is required")
+	}
+
+	accesslistPath := paths.New(*repoPathArgument, *accesslistArgument)
+	exist, _ := accesslistPath.ExistCheck()
+	if !exist {
+		errorExit("Access control file not found")
+	}
+
+	rawAccessList, err := accesslistPath.ReadFile()
+	if err != nil {
+		errorExit(fmt.Sprintf("Unable to read access control file: %s", err))
+	}
+
+	diagnostics := validateAccessList(rawAccessList)
+	for _, diagnostic := range diagnostics {
+		fmt.Fprintf(os.Stderr, "%s:%d: %s\n", *accesslistArgument, diagnostic.Line, diagnostic.Message)
+	}
+
+	if len(diagnostics) > 0 {
+		os.Exit(1)
+	}
+}
+
+// validateAccessList returns the problems found in the access control file data, sorted by line number.
+func validateAccessList(rawAccessList []byte) []accessListDiagnosticType {
+	var diagnostics []accessListDiagnosticType
+	addDiagnostic := func(line int, format string, a ...interface{}) {
+		diagnostics = append(diagnostics, accessListDiagnosticType{Line: line, Message: fmt.Sprintf(format, a...)})
+	}
+
+	var document yaml.Node
+	err := yaml.Unmarshal(rawAccessList, &document)
+	if err != nil {
+		line := 1
+		if lineMatch := yamlErrorLineRegexp.FindStringSubmatch(err.Error()); lineMatch != nil {
+			line, _ = strconv.Atoi(lineMatch[1])
+		}
+		addDiagnostic(line, "Invalid YAML: %s", err)
+		return diagnostics
+	}
+
+	if len(document.Content) == 0 {
+		return nil // An empty file is a valid empty access list.
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.SequenceNode {
+		addDiagnostic(root.Line, "Access control file must contain a list of entries")
+		return diagnostics
+	}
+
+	knownKeys := []string{"access", "host", "name", "repository", "pattern", "regex", "reference", "since", "until"}
+
+	entryLines := make(map[string]int)      // Line of the first entry for each scope key.
+	gitHubNames := make(map[string]string)  // Case folded GitHub account name to the name as written.
+	gitHubNameLines := make(map[string]int) // Case folded GitHub account name to the line of the first entry.
+	for _, entryNode := range root.Content {
+		if entryNode.Kind != yaml.MappingNode {
+			addDiagnostic(entryNode.Line, "Entry must be a mapping of keys to values")
+			continue
+		}
+
+		var accessData accessDataType
+		valueNodes := make(map[string]*yaml.Node)
+		for keyIndex := 0; keyIndex+1 < len(entryNode.Content); keyIndex += 2 {
+			keyNode := entryNode.Content[keyIndex]
+			valueNode := entryNode.Content[keyIndex+1]
+			if !slices.Contains(knownKeys, keyNode.Value) {
+				addDiagnostic(keyNode.Line, "Unknown key `%s`", keyNode.Value)
+				continue
+			}
+			if _, duplicate := valueNodes[keyNode.Value]; duplicate {
+				addDiagnostic(keyNode.Line, "Duplicate key `%s`", keyNode.Value)
+				continue
+			}
+			valueNodes[keyNode.Value] = valueNode
+
+			switch keyNode.Value {
+			case "since", "until":
+				var timestamp time.Time
+				if err := valueNode.Decode(&timestamp); err != nil {
+					addDiagnostic(valueNode.Line, "Invalid `%s` timestamp `%s`", keyNode.Value, valueNode.Value)
+					continue
+				}
+				if keyNode.Value == "since" {
+					accessData.Since = &timestamp
+				} else {
+					accessData.Until = &timestamp
+				}
+			default:
+				if valueNode.Kind != yaml.ScalarNode {
+					addDiagnostic(valueNode.Line, "Value of `%s` must be a string", keyNode.Value)
+					continue
+				}
+				switch keyNode.Value {
+				case "access":
+					accessData.Access = accessType(valueNode.Value)
+				case "host":
+					accessData.Host = valueNode.Value
+				case "name":
+					accessData.Name = valueNode.Value
+				case "repository":
+					accessData.Repository = valueNode.Value
+				case "pattern":
+					accessData.Pattern = valueNode.Value
+				case "regex":
+					accessData.Regex = valueNode.Value
+				case "reference":
+					accessData.Reference = valueNode.Value
+				}
+			}
+		}
+
+		lineOf := func(key string) int {
+			if valueNode, ok := valueNodes[key]; ok {
+				return valueNode.Line
+			}
+			return entryNode.Line
+		}
+
+		switch {
+		case valueNodes["access"] == nil:
+			addDiagnostic(entryNode.Line, "Missing required key `access`")
+		case accessData.Access != Allow && accessData.Access != Default && accessData.Access != Deny:
+			addDiagnostic(lineOf("access"), "Invalid access level `%s` (must be one of `%s`, `%s`, `%s`)", accessData.Access, Allow, Default, Deny)
+		}
+
+		if accessData.scope() == patternAccessScope {
+			if accessData.Host != "" || accessData.Name != "" {
+				addDiagnostic(entryNode.Line, "Pattern entries must not have `host` or `name` keys")
+			}
+		} else {
+			if accessData.Host == "" {
+				addDiagnostic(entryNode.Line, "Missing required key `host`")
+			}
+			if accessData.Name == "" {
+				addDiagnostic(entryNode.Line, "Missing required key `name`")
+			}
+		}
+		if accessData.Repository != "" && (accessData.Pattern != "" || accessData.Regex != "") {
+			addDiagnostic(entryNode.Line, "Repository entries must not have `pattern` or `regex` keys")
+		}
+		if err := compileAccessPatterns([]accessDataType{{Pattern: accessData.Pattern}}); err != nil {
+			addDiagnostic(lineOf("pattern"), "%s", err)
+		}
+		if err := compileAccessPatterns([]accessDataType{{Regex: accessData.Regex}}); err != nil {
+			addDiagnostic(lineOf("regex"), "%s", err)
+		}
+
+		if accessData.Reference == "" {
+			addDiagnostic(entryNode.Line, "Missing required key `reference`")
+		} else if referenceURL, err := url.Parse(accessData.Reference); err != nil || (referenceURL.Scheme != "http" && referenceURL.Scheme != "https") || referenceURL.Host == "" {
+			addDiagnostic(lineOf("reference"), "Reference `%s` is not an http(s) URL", accessData.Reference)
+		}
+
+		if accessData.Since != nil && accessData.Until != nil && !accessData.Since.Before(*accessData.Until) {
+			addDiagnostic(lineOf("until"), "`until` must be later than `since`")
+		}
+
+		// Check for multiple entries for the same library repositories.
+		scopeKey := strings.Join([]string{accessData.Host, accessData.Name, accessData.Repository, accessData.Pattern, accessData.Regex}, "\x00")
+		if firstLine, duplicate := entryLines[scopeKey]; duplicate {
+			addDiagnostic(entryNode.Line, "Duplicate of the entry at line %d", firstLine)
+		} else {
+			entryLines[scopeKey] = entryNode.Line
+		}
+
+		// GitHub account names are case-insensitive, so names that differ only in case refer to the same account.
+		if accessData.Host == "github.com" && accessData.Name != "" {
+			foldedName := strings.ToLower(accessData.Name)
+			if name, found := gitHubNames[foldedName]; found && name != accessData.Name {
+				addDiagnostic(lineOf("name"), "GitHub account name `%s` collides with `%s` at line %d (GitHub account names are case-insensitive)", accessData.Name, name, gitHubNameLines[foldedName])
+			} else if !found {
+				gitHubNames[foldedName] = accessData.Name
+				gitHubNameLines[foldedName] = lineOf("name")
+			}
+		}
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool { return diagnostics[i].Line < diagnostics[j].Line })
+
+	return diagnostics
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validateAccessList(t *testing.T) {
+	rawAccessList := []byte(`- access: deny
+  host: github.com
+  name: foo
+  reference: https://example.com
+- access: allow
+  host: github.com
+  name: bar
+  repository: baz
+  reference: https://example.com
+- access: deny
+  pattern: github.com/*/*-fork
+  reference: https://example.com
+  since: 2021-01-01
+  until: 2021-02-01
+`)
+	assert.Empty(t, validateAccessList(rawAccessList), "Valid")
+	assert.Empty(t, validateAccessList([]byte("")), "Empty")
+	assert.Empty(t, validateAccessList([]byte("[]\n")), "Empty list")
+
+	testTables := []struct {
+		testName            string
+		rawAccessList       string
+		expectedDiagnostics []accessListDiagnosticType
+	}{
+		{
+			"Invalid YAML",
+			"- access: deny\n  host: github.com\n  name: foo\n reference: https://example.com\n",
+			[]accessListDiagnosticType{{Line: 3, Message: "Invalid YAML: yaml: line 3: did not find expected '-' indicator"}},
+		},
+		{
+			"Not a list",
+			"access: deny\n",
+			[]accessListDiagnosticType{{Line: 1, Message: "Access control file must contain a list of entries"}},
+		},
+		{
+			"Invalid access",
+			"- access: denyy\n  host: github.com\n  name: foo\n  reference: https://example.com\n",
+			[]accessListDiagnosticType{{Line: 1, Message: "Invalid access level `denyy` (must be one of `allow`, `default`, `deny`)"}},
+		},
+		{
+			"Missing keys",
+			"- host: github.com\n  name: foo\n- access: deny\n  name: foo\n",
+			[]accessListDiagnosticType{
+				{Line: 1, Message: "Missing required key `access`"},
+				{Line: 1, Message: "Missing required key `reference`"},
+				{Line: 3, Message: "Missing required key `host`"},
+				{Line: 3, Message: "Missing required key `reference`"},
+			},
+		},
+		{
+			"Unknown key",
+			"- access: deny\n  host: github.com\n  name: foo\n  refrence: https://example.com\n",
+			[]accessListDiagnosticType{
+				{Line: 1, Message: "Missing required key `reference`"},
+				{Line: 4, Message: "Unknown key `refrence`"},
+			},
+		},
+		{
+			"Invalid reference",
+			"- access: deny\n  host: github.com\n  name: foo\n  reference: example.com\n",
+			[]accessListDiagnosticType{{Line: 4, Message: "Reference `example.com` is not an http(s) URL"}},
+		},
+		{
+			"Invalid timestamps",
+			"- access: deny\n  host: github.com\n  name: foo\n  reference: https://example.com\n  since: tomorrow\n  until: 2021-01-01\n" +
+				"- access: deny\n  host: github.com\n  name: bar\n  reference: https://example.com\n  since: 2021-02-01\n  until: 2021-01-01\n",
+			[]accessListDiagnosticType{
+				{Line: 5, Message: "Invalid `since` timestamp `tomorrow`"},
+				{Line: 12, Message: "`until` must be later than `since`"},
+			},
+		},
+		{
+			"Invalid patterns",
+			"- access: deny\n  pattern: github.com/[foo\n  reference: https://example.com\n- access: deny\n  regex: github.com/(foo\n  host: github.com\n  reference: https://example.com\n",
+			[]accessListDiagnosticType{
+				{Line: 2, Message: "Invalid pattern github.com/[foo: syntax error in pattern"},
+				{Line: 4, Message: "Pattern entries must not have `host` or `name` keys"},
+				{Line: 5, Message: "Invalid regex github.com/(foo: error parsing regexp: missing closing ): `^(?:github.com/(foo)$`"},
+			},
+		},
+		{
+			"Duplicate entry",
+			"- access: deny\n  host: github.com\n  name: foo\n  reference: https://example.com\n- access: allow\n  host: github.com\n  name: foo\n  reference: https://example.com\n",
+			[]accessListDiagnosticType{{Line: 5, Message: "Duplicate of the entry at line 1"}},
+		},
+		{
+			"Case-insensitive GitHub name collision",
+			"- access: deny\n  host: github.com\n  name: Foo\n  reference: https://example.com\n- access: allow\n  host: github.com\n  name: foo\n  reference: https://example.com\n",
+			[]accessListDiagnosticType{{Line: 7, Message: "GitHub account name `foo` collides with `Foo` at line 3 (GitHub account names are case-insensitive)"}},
+		},
+	}
+
+	for _, testTable := range testTables {
+		assert.Equal(t, testTable.expectedDiagnostics, validateAccessList([]byte(testTable.rawAccessList)), testTable.testName)
+	}
+}
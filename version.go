@@ -0,0 +1,178 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// versionRegexp matches versions compliant with the relaxed semver specification used by Library Manager, where the
+// minor and patch components are optional.
+var versionRegexp = regexp.MustCompile(`^(0|[1-9][0-9]*)(?:\.(0|[1-9][0-9]*))?(?:\.(0|[1-9][0-9]*))?(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?$`)
+
+// versionType is the type of a parsed library version.
+type versionType struct {
+	major      uint64
+	minor      uint64
+	patch      uint64
+	prerelease []string
+	build      string
+}
+
+// parseVersion parses a version string according to the relaxed semver specification.
+func parseVersion(rawVersion string) (versionType, error) {
+	var version versionType
+	match := versionRegexp.FindStringSubmatch(rawVersion)
+	if match == nil {
+		return version, fmt.Errorf("%s is not a valid semver version", rawVersion)
+	}
+
+	for componentIndex, component := range []*uint64{&version.major, &version.minor, &version.patch} {
+		if match[componentIndex+1] == "" {
+			continue
+		}
+		value, err := strconv.ParseUint(match[componentIndex+1], 10, 64)
+		if err != nil {
+			return version, fmt.Errorf("%s is not a valid semver version: %s", rawVersion, err)
+		}
+		*component = value
+	}
+
+	if match[4] != "" {
+		version.prerelease = strings.Split(match[4], ".")
+		for _, identifier := range version.prerelease {
+			if len(identifier) > 1 && identifier[0] == '0' && isNumeric(identifier) {
+				return version, fmt.Errorf("%s is not a valid semver version: numeric pre-release identifier %s has a leading zero", rawVersion, identifier)
+			}
+		}
+	}
+	version.build = match[5]
+
+	return version, nil
+}
+
+// String returns the canonical representation of the version.
+func (version versionType) String() string {
+	versionString := fmt.Sprintf("%d.%d.%d", version.major, version.minor, version.patch)
+	if version.prerelease != nil {
+		versionString += "-" + strings.Join(version.prerelease, ".")
+	}
+	if version.build != "" {
+		versionString += "+" + version.build
+	}
+
+	return versionString
+}
+
+// isPrerelease returns whether the version is a pre-release.
+func (version versionType) isPrerelease() bool {
+	return version.prerelease != nil
+}
+
+// compare returns -1, 0, or 1 according to whether the version has lower, equal, or higher precedence than the other
+// version. Build metadata is ignored, as specified by semver.
+func (version versionType) compare(other versionType) int {
+	for _, components := range [][2]uint64{{version.major, other.major}, {version.minor, other.minor}, {version.patch, other.patch}} {
+		if components[0] != components[1] {
+			if components[0] < components[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	// A pre-release version has lower precedence than the associated normal version.
+	switch {
+	case version.prerelease == nil && other.prerelease == nil:
+		return 0
+	case version.prerelease == nil:
+		return 1
+	case other.prerelease == nil:
+		return -1
+	}
+
+	for identifierIndex := 0; identifierIndex < len(version.prerelease) && identifierIndex < len(other.prerelease); identifierIndex++ {
+		if result := comparePrereleaseIdentifiers(version.prerelease[identifierIndex], other.prerelease[identifierIndex]); result != 0 {
+			return result
+		}
+	}
+
+	// A larger set of pre-release fields has higher precedence than a smaller set.
+	switch {
+	case len(version.prerelease) < len(other.prerelease):
+		return -1
+	case len(version.prerelease) > len(other.prerelease):
+		return 1
+	}
+
+	return 0
+}
+
+// comparePrereleaseIdentifiers compares pre-release identifiers according to the semver precedence rules.
+func comparePrereleaseIdentifiers(identifier string, other string) int {
+	identifierIsNumeric := isNumeric(identifier)
+	otherIsNumeric := isNumeric(other)
+	switch {
+	case identifierIsNumeric && otherIsNumeric:
+		// Numeric identifiers are compared by length first to avoid overflow, which is valid since they have no leading
+		// zeros.
+		if len(identifier) != len(other) {
+			if len(identifier) < len(other) {
+				return -1
+			}
+			return 1
+		}
+	case identifierIsNumeric:
+		return -1 // Numeric identifiers have lower precedence than alphanumeric identifiers.
+	case otherIsNumeric:
+		return 1
+	}
+
+	return strings.Compare(identifier, other)
+}
+
+// isNumeric returns whether the string consists only of digits.
+func isNumeric(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, character := range value {
+		if character < '0' || character > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// versionTagMismatchError returns the error message for a library.properties version that does not match the release tag,
+// or an empty string if they match. A `v` prefix on the tag is tolerated.
+func versionTagMismatchError(version string, tag string) string {
+	if version == tag || "v"+version == tag {
+		return ""
+	}
+
+	return fmt.Sprintf("The library.properties `version` value `%s` does not match the release tag `%s`.%%0AThe tag name must be the `version` value, optionally with a `v` prefix. Update the `version` field in library.properties, then create a [release](https://docs.github.com/en/github/administering-a-repository/managing-releases-in-a-repository) or [tag](https://git-scm.com/docs/git-tag) with a matching name.", version, tag)
+}
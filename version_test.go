@@ -0,0 +1,89 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseVersion(t *testing.T) {
+	testTables := []struct {
+		rawVersion      string
+		expectedVersion string
+		errorAssertion  assert.ValueAssertionFunc
+	}{
+		{"1.2.3", "1.2.3", assert.Nil},
+		{"1.2", "1.2.0", assert.Nil},
+		{"1", "1.0.0", assert.Nil},
+		{"1.2.3-rc.1", "1.2.3-rc.1", assert.Nil},
+		{"1.2.3-beta+exp.sha.5114f85", "1.2.3-beta+exp.sha.5114f85", assert.Nil},
+		{"1.2.3+build", "1.2.3+build", assert.Nil},
+		{"v1.2.3", "", assert.NotNil},
+		{"1.2.3.4", "", assert.NotNil},
+		{"01.2.3", "", assert.NotNil},
+		{"1.2.3-01", "", assert.NotNil},
+		{"1.2.3-", "", assert.NotNil},
+		{"foo", "", assert.NotNil},
+		{"", "", assert.NotNil},
+	}
+
+	for _, testTable := range testTables {
+		version, err := parseVersion(testTable.rawVersion)
+		testTable.errorAssertion(t, err, testTable.rawVersion)
+		if err == nil {
+			assert.Equal(t, testTable.expectedVersion, version.String(), testTable.rawVersion)
+		}
+	}
+}
+
+func TestVersion_compare(t *testing.T) {
+	// In order of increasing precedence, per the semver specification.
+	orderedVersions := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1", "1.10.0", "2"}
+	for lowerIndex, rawLowerVersion := range orderedVersions {
+		lowerVersion, err := parseVersion(rawLowerVersion)
+		require.Nil(t, err)
+		assert.Equal(t, 0, lowerVersion.compare(lowerVersion), rawLowerVersion)
+		for _, rawHigherVersion := range orderedVersions[lowerIndex+1:] {
+			higherVersion, err := parseVersion(rawHigherVersion)
+			require.Nil(t, err)
+			assert.Equal(t, -1, lowerVersion.compare(higherVersion), "%s < %s", rawLowerVersion, rawHigherVersion)
+			assert.Equal(t, 1, higherVersion.compare(lowerVersion), "%s > %s", rawHigherVersion, rawLowerVersion)
+		}
+	}
+
+	version, err := parseVersion("1.0.0+foo")
+	require.Nil(t, err)
+	otherVersion, err := parseVersion("1.0.0+bar")
+	require.Nil(t, err)
+	assert.Equal(t, 0, version.compare(otherVersion), "Build metadata is ignored")
+}
+
+func Test_versionTagMismatchError(t *testing.T) {
+	assert.Empty(t, versionTagMismatchError("1.2.3", "1.2.3"))
+	assert.Empty(t, versionTagMismatchError("1.2.3", "v1.2.3"))
+	assert.Contains(t, versionTagMismatchError("1.2.3", "1.2.4"), "The library.properties `version` value `1.2.3` does not match the release tag `1.2.4`.")
+	assert.NotEmpty(t, versionTagMismatchError("1.2.3", "V1.2.3"))
+	assert.NotEmpty(t, versionTagMismatchError("1.2.3", "release-1.2.3"))
+}
@@ -0,0 +1,218 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// versionConstraint is the interface for the version constraints of library dependencies, as used in the
+// library.properties `depends` field (e.g., `>=1.0.0 && <2.0.0`).
+type versionConstraint interface {
+	// match returns whether the version satisfies the constraint.
+	match(version versionType) bool
+}
+
+// versionComparisonType is the constraint that compares the version to a reference version.
+type versionComparisonType struct {
+	operator string      // Comparison operator: `=`, `>`, `>=`, `<`, or `<=`.
+	version  versionType // Reference version.
+}
+
+func (constraint versionComparisonType) match(version versionType) bool {
+	result := version.compare(constraint.version)
+	switch constraint.operator {
+	case "=":
+		return result == 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	default: // "<="
+		return result <= 0
+	}
+}
+
+// versionConstraintNotType is the constraint that is satisfied when the operand constraint is not.
+type versionConstraintNotType struct {
+	operand versionConstraint
+}
+
+func (constraint versionConstraintNotType) match(version versionType) bool {
+	return !constraint.operand.match(version)
+}
+
+// versionConstraintAndType is the constraint that is satisfied when all the operand constraints are.
+type versionConstraintAndType struct {
+	operands []versionConstraint
+}
+
+func (constraint versionConstraintAndType) match(version versionType) bool {
+	for _, operand := range constraint.operands {
+		if !operand.match(version) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// versionConstraintOrType is the constraint that is satisfied when any of the operand constraints is.
+type versionConstraintOrType struct {
+	operands []versionConstraint
+}
+
+func (constraint versionConstraintOrType) match(version versionType) bool {
+	for _, operand := range constraint.operands {
+		if operand.match(version) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// versionConstraintParserType is the type of the state of the version constraint parser.
+type versionConstraintParserType struct {
+	input    string // Constraint being parsed.
+	position int    // Index of the next character of the input.
+}
+
+// parseVersionConstraint parses a version constraint. The syntax is comparisons of the form `<operator><version>`
+// combined with `!`, `&&`, `||`, and parentheses, where `&&` has higher precedence than `||`.
+func parseVersionConstraint(rawConstraint string) (versionConstraint, error) {
+	parser := versionConstraintParserType{input: rawConstraint}
+	constraint, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	parser.skipSpace()
+	if parser.position < len(parser.input) {
+		return nil, fmt.Errorf("unexpected `%s`", parser.input[parser.position:])
+	}
+
+	return constraint, nil
+}
+
+func (parser *versionConstraintParserType) parseOr() (versionConstraint, error) {
+	var operands []versionConstraint
+	for {
+		operand, err := parser.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		if !parser.consume("||") {
+			break
+		}
+	}
+
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return versionConstraintOrType{operands: operands}, nil
+}
+
+func (parser *versionConstraintParserType) parseAnd() (versionConstraint, error) {
+	var operands []versionConstraint
+	for {
+		operand, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		if !parser.consume("&&") {
+			break
+		}
+	}
+
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return versionConstraintAndType{operands: operands}, nil
+}
+
+func (parser *versionConstraintParserType) parseUnary() (versionConstraint, error) {
+	if parser.consume("!") {
+		operand, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return versionConstraintNotType{operand: operand}, nil
+	}
+
+	if parser.consume("(") {
+		constraint, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !parser.consume(")") {
+			return nil, fmt.Errorf("missing `)`")
+		}
+		return constraint, nil
+	}
+
+	for _, operator := range []string{">=", "<=", "=", ">", "<"} { // Two character operators must be tried first.
+		if parser.consume(operator) {
+			parser.skipSpace()
+			start := parser.position
+			for parser.position < len(parser.input) && strings.ContainsRune("0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-+", rune(parser.input[parser.position])) {
+				parser.position++
+			}
+			if start == parser.position {
+				return nil, fmt.Errorf("missing version after `%s`", operator)
+			}
+			version, err := parseVersion(parser.input[start:parser.position])
+			if err != nil {
+				return nil, err
+			}
+			return versionComparisonType{operator: operator, version: version}, nil
+		}
+	}
+
+	parser.skipSpace()
+	if parser.position >= len(parser.input) {
+		return nil, fmt.Errorf("unexpected end of constraint")
+	}
+	return nil, fmt.Errorf("expected an operator at `%s`", parser.input[parser.position:])
+}
+
+// consume skips whitespace, then advances past the token and returns true if the input continues with the token.
+func (parser *versionConstraintParserType) consume(token string) bool {
+	parser.skipSpace()
+	if strings.HasPrefix(parser.input[parser.position:], token) {
+		parser.position += len(token)
+		return true
+	}
+
+	return false
+}
+
+// skipSpace advances past any whitespace.
+func (parser *versionConstraintParserType) skipSpace() {
+	for parser.position < len(parser.input) && (parser.input[parser.position] == ' ' || parser.input[parser.position] == '\t') {
+		parser.position++
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 ARDUINO SA (http://www.arduino.cc/)
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// You can be released from the requirements of the above licenses by purchasing
+// a commercial license. Buying such a license is mandatory if you want to
+// modify or otherwise use the software for commercial activities involving the
+// Arduino software without disclosing the source code of your own applications.
+// To purchase a commercial license, send an email to license@arduino.cc.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseVersionConstraint(t *testing.T) {
+	testTables := []struct {
+		constraint        string
+		matchingVersions  []string
+		excludedVersions  []string
+		expectedErrString string
+	}{
+		{constraint: "=1.0.0", matchingVersions: []string{"1.0.0", "1.0"}, excludedVersions: []string{"1.0.1"}},
+		{constraint: ">1.0.0", matchingVersions: []string{"1.0.1", "2.0.0"}, excludedVersions: []string{"1.0.0", "1.0.0-rc1"}},
+		{constraint: ">=1.0.0", matchingVersions: []string{"1.0.0", "1.1.0"}, excludedVersions: []string{"1.0.0-rc1", "0.9.0"}},
+		{constraint: "<1.0.0", matchingVersions: []string{"0.9.0", "1.0.0-rc1"}, excludedVersions: []string{"1.0.0"}},
+		{constraint: "<=1.0.0", matchingVersions: []string{"1.0.0", "0.1.0"}, excludedVersions: []string{"1.0.1"}},
+		{constraint: "!=1.0.0", matchingVersions: []string{"1.0.1"}, excludedVersions: []string{"1.0.0"}},
+		{constraint: ">=1.0.0 && <2.0.0", matchingVersions: []string{"1.5.0"}, excludedVersions: []string{"2.0.0", "0.5.0"}},
+		{constraint: "<1.0.0 || >=2.0.0 && <3.0.0", matchingVersions: []string{"0.5.0", "2.5.0"}, excludedVersions: []string{"1.5.0", "3.0.0"}},
+		{constraint: "(<1.0.0 || >=2.0.0) && <3.0.0", matchingVersions: []string{"0.5.0", "2.5.0"}, excludedVersions: []string{"1.5.0", "3.0.0"}},
+		{constraint: "!(>=1.0.0 && <2.0.0)", matchingVersions: []string{"0.5.0", "2.0.0"}, excludedVersions: []string{"1.5.0"}},
+		{constraint: "", expectedErrString: "unexpected end of constraint"},
+		{constraint: "1.0.0", expectedErrString: "expected an operator at `1.0.0`"},
+		{constraint: ">=", expectedErrString: "missing version after `>=`"},
+		{constraint: ">=1.0.0.0", expectedErrString: "1.0.0.0 is not a valid semver version"},
+		{constraint: "(>=1.0.0", expectedErrString: "missing `)`"},
+		{constraint: ">=1.0.0 <2.0.0", expectedErrString: "unexpected `<2.0.0`"},
+		{constraint: ">=1.0.0 &&", expectedErrString: "unexpected end of constraint"},
+	}
+
+	for _, testTable := range testTables {
+		constraint, err := parseVersionConstraint(testTable.constraint)
+		if testTable.expectedErrString != "" {
+			assert.EqualError(t, err, testTable.expectedErrString, testTable.constraint)
+			continue
+		}
+		require.Nil(t, err, testTable.constraint)
+
+		for _, rawVersion := range testTable.matchingVersions {
+			version, err := parseVersion(rawVersion)
+			require.Nil(t, err)
+			assert.True(t, constraint.match(version), "%s matches %s", rawVersion, testTable.constraint)
+		}
+		for _, rawVersion := range testTable.excludedVersions {
+			version, err := parseVersion(rawVersion)
+			require.Nil(t, err)
+			assert.False(t, constraint.match(version), "%s does not match %s", rawVersion, testTable.constraint)
+		}
+	}
+}